@@ -0,0 +1,218 @@
+package rcon
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// ErrCircuitOpen is returned when the circuit breaker of the server is open.
+// The returned error is *CircuitOpenError which wraps ErrCircuitOpen.
+var ErrCircuitOpen = errors.New("circuit breaker is open")
+
+// CircuitOpenError is returned by Dial and Execute when the circuit breaker
+// of the server is open.
+type CircuitOpenError struct {
+	// Address is the server address.
+	Address string
+
+	// RetryAfter is the time left until the circuit breaker half-opens.
+	RetryAfter time.Duration
+}
+
+// Error implements error.
+func (e *CircuitOpenError) Error() string {
+	return fmt.Sprintf("rcon: %s: %s, retry after %s", ErrCircuitOpen, e.Address, e.RetryAfter)
+}
+
+// Unwrap returns ErrCircuitOpen.
+func (e *CircuitOpenError) Unwrap() error {
+	return ErrCircuitOpen
+}
+
+// CircuitState is the state of the circuit breaker of a server.
+type CircuitState int
+
+// Circuit breaker states.
+const (
+	// CircuitClosed means requests are passed to the server.
+	CircuitClosed CircuitState = iota
+
+	// CircuitOpen means requests fail fast with *CircuitOpenError.
+	CircuitOpen
+
+	// CircuitHalfOpen means a single trial request is passed to the server,
+	// other requests fail fast.
+	CircuitHalfOpen
+)
+
+// String returns the state name.
+func (s CircuitState) String() string {
+	switch s {
+	case CircuitClosed:
+		return "closed"
+	case CircuitOpen:
+		return "open"
+	case CircuitHalfOpen:
+		return "half-open"
+	default:
+		return fmt.Sprintf("CircuitState(%d)", int(s))
+	}
+}
+
+// CircuitBreaker tracks failures of Dial and Execute per server address.
+// The circuit of a server opens after threshold consecutive failures, then
+// Dial and Execute fail fast until cooldown passes. After that the circuit
+// half-opens and lets a single trial request through: its success closes
+// the circuit, its failure opens it again. A CircuitBreaker is shared by all
+// connections created with the same SetCircuitBreaker option.
+type CircuitBreaker struct {
+	threshold int
+	cooldown  time.Duration
+	handler   func(address string, state CircuitState)
+
+	mu       sync.Mutex
+	circuits map[string]*circuit
+}
+
+// circuit is the circuit breaker state of a server.
+type circuit struct {
+	state    CircuitState
+	failures int
+	openedAt time.Time
+	trial    bool
+}
+
+// NewCircuitBreaker creates CircuitBreaker which opens after threshold
+// consecutive failures and half-opens after cooldown. The handler is called
+// with the new state on each transition, it may be nil. The handler must not
+// block.
+func NewCircuitBreaker(
+	threshold int, cooldown time.Duration, handler func(address string, state CircuitState),
+) *CircuitBreaker {
+	return &CircuitBreaker{
+		threshold: max(threshold, 1),
+		cooldown:  cooldown,
+		handler:   handler,
+		circuits:  make(map[string]*circuit),
+	}
+}
+
+// State returns the circuit state of the server.
+func (b *CircuitBreaker) State(address string) CircuitState {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if c, ok := b.circuits[address]; ok {
+		return c.state
+	}
+
+	return CircuitClosed
+}
+
+// allow returns *CircuitOpenError if the request to the server must fail
+// fast. The allowed request must be reported with done. Nil CircuitBreaker
+// allows all requests.
+func (b *CircuitBreaker) allow(address string) error {
+	if b == nil {
+		return nil
+	}
+
+	b.mu.Lock()
+
+	c, ok := b.circuits[address]
+	if !ok {
+		c = &circuit{}
+		b.circuits[address] = c
+	}
+
+	var (
+		err        error
+		transition bool
+	)
+
+	switch c.state {
+	case CircuitClosed:
+	case CircuitOpen:
+		if wait := b.cooldown - time.Since(c.openedAt); wait > 0 {
+			err = &CircuitOpenError{Address: address, RetryAfter: wait}
+
+			break
+		}
+
+		c.state, c.trial, transition = CircuitHalfOpen, true, true
+	case CircuitHalfOpen:
+		if c.trial {
+			err = &CircuitOpenError{Address: address}
+		} else {
+			c.trial = true
+		}
+	}
+
+	b.mu.Unlock()
+
+	if transition {
+		b.notify(address, CircuitHalfOpen)
+	}
+
+	return err
+}
+
+// done reports the result of the allowed request to the server.
+func (b *CircuitBreaker) done(address string, err error) {
+	if b == nil {
+		return
+	}
+
+	b.mu.Lock()
+
+	c := b.circuits[address]
+	state := c.state
+
+	switch {
+	case !isCircuitFailure(err):
+		if err == nil {
+			c.state, c.failures = CircuitClosed, 0
+		}
+	case c.state == CircuitHalfOpen:
+		c.state, c.openedAt = CircuitOpen, time.Now()
+	case c.state == CircuitClosed:
+		if c.failures++; c.failures >= b.threshold {
+			c.state, c.openedAt = CircuitOpen, time.Now()
+		}
+	}
+
+	if state == CircuitHalfOpen {
+		c.trial = false
+	}
+
+	transition := c.state != state
+	state = c.state
+
+	b.mu.Unlock()
+
+	if transition {
+		b.notify(address, state)
+	}
+}
+
+// notify calls the state handler.
+func (b *CircuitBreaker) notify(address string, state CircuitState) {
+	if b.handler != nil {
+		b.handler(address, state)
+	}
+}
+
+// isCircuitFailure reports whether err means the server is unavailable.
+// Errors caused by the caller, like wrong password, invalid command, rate
+// limit or cancelled context, are not failures.
+func isCircuitFailure(err error) bool {
+	return err != nil &&
+		!errors.Is(err, ErrAuthFailed) &&
+		!errors.Is(err, ErrCommandEmpty) &&
+		!errors.Is(err, ErrCommandTooLong) &&
+		!errors.Is(err, ErrRateLimited) &&
+		!errors.Is(err, context.Canceled)
+}
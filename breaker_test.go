@@ -0,0 +1,153 @@
+package rcon_test
+
+import (
+	"context"
+	"errors"
+	"net"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/gorcon/rcon"
+	"github.com/gorcon/rcon/rcontest"
+)
+
+// flakyDialer fails the first fails dials.
+type flakyDialer struct {
+	fails atomic.Int32
+}
+
+func (d *flakyDialer) DialContext(ctx context.Context, network string, address string) (net.Conn, error) {
+	if d.fails.Add(-1) >= 0 {
+		return nil, errors.New("connection refused")
+	}
+
+	return (&net.Dialer{}).DialContext(ctx, network, address)
+}
+
+func TestCircuitBreaker(t *testing.T) {
+	server := rcontest.NewServer(
+		rcontest.SetSettings(rcontest.Settings{Password: "password"}),
+		rcontest.SetCommandHandler(commandHandler),
+	)
+	defer server.Close()
+
+	var (
+		mu     sync.Mutex
+		states []rcon.CircuitState
+	)
+
+	breaker := rcon.NewCircuitBreaker(2, 50*time.Millisecond, func(address string, state rcon.CircuitState) {
+		if address != server.Addr() {
+			t.Errorf("got address %q, want %q", address, server.Addr())
+		}
+
+		mu.Lock()
+		states = append(states, state)
+		mu.Unlock()
+	})
+
+	conn, err := rcon.Dial(server.Addr(), "password", rcon.SetCircuitBreaker(breaker))
+	if err != nil {
+		t.Fatalf("got err %q, want %v", err, nil)
+	}
+	defer conn.Close()
+
+	dialer := &flakyDialer{}
+	dialer.fails.Store(3)
+
+	options := []rcon.Option{rcon.SetCircuitBreaker(breaker), rcon.SetDialer(dialer)}
+
+	for range 2 {
+		if _, err := rcon.Dial(server.Addr(), "password", options...); err == nil {
+			t.Fatalf("got err %v, want dial error", err)
+		}
+	}
+
+	if state := breaker.State(server.Addr()); state != rcon.CircuitOpen {
+		t.Fatalf("got state %s, want %s", state, rcon.CircuitOpen)
+	}
+
+	t.Run("fail fast", func(t *testing.T) {
+		_, err := rcon.Dial(server.Addr(), "password", options...)
+
+		var openErr *rcon.CircuitOpenError
+		if !errors.As(err, &openErr) || !errors.Is(err, rcon.ErrCircuitOpen) {
+			t.Fatalf("got err %q, want %q", err, rcon.ErrCircuitOpen)
+		}
+
+		if openErr.Address != server.Addr() || openErr.RetryAfter <= 0 {
+			t.Errorf("got %+v, want address %q and positive retry after", openErr, server.Addr())
+		}
+
+		if _, err := conn.Execute("help"); !errors.Is(err, rcon.ErrCircuitOpen) {
+			t.Errorf("got err %q, want %q", err, rcon.ErrCircuitOpen)
+		}
+
+		if got := dialer.fails.Load(); got != 1 {
+			t.Errorf("got %d dials left to fail, want %d", got, 1)
+		}
+	})
+
+	t.Run("half-open failure", func(t *testing.T) {
+		time.Sleep(60 * time.Millisecond)
+
+		if _, err := rcon.Dial(server.Addr(), "password", options...); err == nil ||
+			errors.Is(err, rcon.ErrCircuitOpen) {
+			t.Fatalf("got err %v, want dial error", err)
+		}
+
+		if state := breaker.State(server.Addr()); state != rcon.CircuitOpen {
+			t.Errorf("got state %s, want %s", state, rcon.CircuitOpen)
+		}
+	})
+
+	t.Run("half-open success", func(t *testing.T) {
+		time.Sleep(60 * time.Millisecond)
+
+		if _, err := conn.Execute("help"); err != nil {
+			t.Fatalf("got err %q, want %v", err, nil)
+		}
+
+		if state := breaker.State(server.Addr()); state != rcon.CircuitClosed {
+			t.Errorf("got state %s, want %s", state, rcon.CircuitClosed)
+		}
+	})
+
+	mu.Lock()
+	defer mu.Unlock()
+
+	want := []rcon.CircuitState{
+		rcon.CircuitOpen, rcon.CircuitHalfOpen, rcon.CircuitOpen, rcon.CircuitHalfOpen, rcon.CircuitClosed,
+	}
+
+	if len(states) != len(want) {
+		t.Fatalf("got states %v, want %v", states, want)
+	}
+
+	for i := range want {
+		if states[i] != want[i] {
+			t.Errorf("got states %v, want %v", states, want)
+
+			break
+		}
+	}
+}
+
+func TestCircuitBreaker_AuthFailed(t *testing.T) {
+	server := rcontest.NewServer(rcontest.SetSettings(rcontest.Settings{Password: "password"}))
+	defer server.Close()
+
+	breaker := rcon.NewCircuitBreaker(1, time.Minute, nil)
+
+	for range 2 {
+		if _, err := rcon.Dial(server.Addr(), "wrong", rcon.SetCircuitBreaker(breaker)); !errors.Is(err, rcon.ErrAuthFailed) {
+			t.Errorf("got err %q, want %q", err, rcon.ErrAuthFailed)
+		}
+	}
+
+	if state := breaker.State(server.Addr()); state != rcon.CircuitClosed {
+		t.Errorf("got state %s, want %s", state, rcon.CircuitClosed)
+	}
+}
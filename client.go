@@ -0,0 +1,441 @@
+package rcon
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/rand/v2"
+	"sync"
+	"time"
+)
+
+const (
+	// DefaultMinReconnectBackoff is the default delay before the first
+	// reconnect attempt.
+	DefaultMinReconnectBackoff = 100 * time.Millisecond
+
+	// DefaultMaxReconnectBackoff is the default maximum delay between
+	// reconnect attempts.
+	DefaultMaxReconnectBackoff = 30 * time.Second
+)
+
+// ErrClientClosed is returned when executing command on closed Client.
+var ErrClientClosed = errors.New("client closed")
+
+// ClientState is the state of the Client connection.
+type ClientState int
+
+// Client connection states.
+const (
+	// StateDisconnected means the client has no connection to the server.
+	StateDisconnected ClientState = iota
+
+	// StateConnecting means the client dials and authenticates.
+	StateConnecting
+
+	// StateConnected means the client has an authenticated connection.
+	StateConnected
+
+	// StateClosed means the client was closed by Close.
+	StateClosed
+)
+
+// String returns the state name.
+func (s ClientState) String() string {
+	switch s {
+	case StateDisconnected:
+		return "disconnected"
+	case StateConnecting:
+		return "connecting"
+	case StateConnected:
+		return "connected"
+	case StateClosed:
+		return "closed"
+	default:
+		return fmt.Sprintf("ClientState(%d)", int(s))
+	}
+}
+
+// ClientSettings contains option to Client.
+type ClientSettings struct {
+	options      []Option
+	minBackoff   time.Duration
+	maxBackoff   time.Duration
+	maxAttempts  int
+	idempotent   func(command string) bool
+	stateHandler func(state ClientState, err error)
+}
+
+// DefaultClientSettings provides default settings to Client.
+var DefaultClientSettings = ClientSettings{
+	minBackoff: DefaultMinReconnectBackoff,
+	maxBackoff: DefaultMaxReconnectBackoff,
+}
+
+// ClientOption allows to inject settings to ClientSettings.
+type ClientOption func(s *ClientSettings)
+
+// SetConnOptions injects Conn options which are used for each dial to
+// ClientSettings.
+func SetConnOptions(options ...Option) ClientOption {
+	return func(s *ClientSettings) {
+		s.options = options
+	}
+}
+
+// SetReconnectBackoff injects the delay before the first reconnect attempt
+// and the maximum delay between attempts to ClientSettings. The delay is
+// doubled after each failed attempt and randomized by up to a half.
+func SetReconnectBackoff(minBackoff, maxBackoff time.Duration) ClientOption {
+	return func(s *ClientSettings) {
+		s.minBackoff = minBackoff
+		s.maxBackoff = maxBackoff
+	}
+}
+
+// SetMaxReconnectAttempts injects the maximum number of consecutive dial
+// attempts per command to ClientSettings. Zero means no limit, the attempts
+// are made until the command context is done.
+func SetMaxReconnectAttempts(attempts int) ClientOption {
+	return func(s *ClientSettings) {
+		s.maxAttempts = attempts
+	}
+}
+
+// SetIdempotent injects the function which reports whether the command is
+// safe to be sent again after the connection was broken to ClientSettings.
+// Commands are never replayed by default. RetryPolicy.IsSafe may be passed
+// to replay the commands which are safe to retry by the Conn retry policy.
+func SetIdempotent(idempotent func(command string) bool) ClientOption {
+	return func(s *ClientSettings) {
+		s.idempotent = idempotent
+	}
+}
+
+// SetStateHandler injects handler which is called on each Client connection
+// state change to ClientSettings. The err is the reason of the transition to
+// StateDisconnected and nil otherwise.
+func SetStateHandler(handler func(state ClientState, err error)) ClientOption {
+	return func(s *ClientSettings) {
+		s.stateHandler = handler
+	}
+}
+
+// Client is an RCON client which keeps a Conn to the server. When the
+// connection is broken, for example because the server has been restarted,
+// Client re-dials and re-authenticates with exponential backoff on the next
+// command. Client is safe for concurrent use by multiple goroutines.
+type Client struct {
+	address  string
+	password string
+	settings ClientSettings
+
+	// dialLock lets a single goroutine dial, the others wait for it. It is
+	// a channel to be able to stop waiting for it when the command context
+	// is done or Client is closed.
+	dialLock chan struct{}
+
+	// done is closed by Close to abort the dial in progress.
+	done chan struct{}
+
+	// mu guards the fields below. It is never held while dialing.
+	mu     sync.Mutex
+	conn   *Conn
+	state  ClientState
+	closed bool
+
+	// dialed is set after the first dial attempt, the following attempts
+	// are reported to metrics as reconnects.
+	dialed bool
+}
+
+// NewClient creates a new Client for the server address. The connection is
+// established on the first command.
+func NewClient(address string, password string, options ...ClientOption) *Client {
+	settings := DefaultClientSettings
+	for _, option := range options {
+		option(&settings)
+	}
+
+	return &Client{
+		address:  address,
+		password: password,
+		settings: settings,
+		dialLock: make(chan struct{}, 1),
+		done:     make(chan struct{}),
+	}
+}
+
+// Execute sends command to the server like Conn.Execute, reconnecting to
+// the server if required.
+func (c *Client) Execute(command string) (string, error) {
+	return c.ExecuteContext(context.Background(), command)
+}
+
+// ExecuteContext sends command to the server like Conn.ExecuteContext,
+// reconnecting to the server if required. When the connection is broken
+// while the command is executed, the error is returned, unless the command
+// is idempotent, in which case it is sent once again over a new connection.
+func (c *Client) ExecuteContext(ctx context.Context, command string) (string, error) {
+	conn, err := c.connect(ctx)
+	if err != nil {
+		return "", err
+	}
+
+	response, err := conn.ExecuteContext(ctx, command)
+	if err == nil || !conn.broken() {
+		return response, err
+	}
+
+	c.disconnect(conn, err)
+
+	if c.settings.idempotent == nil || !c.settings.idempotent(command) {
+		return response, err
+	}
+
+	if conn, err = c.connect(ctx); err != nil {
+		return "", err
+	}
+
+	response, err = conn.ExecuteContext(ctx, command)
+	if err != nil && conn.broken() {
+		c.disconnect(conn, err)
+	}
+
+	return response, err
+}
+
+// State returns the current Client connection state.
+func (c *Client) State() ClientState {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return c.state
+}
+
+// Close closes the Client connection and aborts the reconnect in progress.
+// Commands executed after Close fail with ErrClientClosed.
+func (c *Client) Close() error {
+	c.mu.Lock()
+
+	if c.closed {
+		c.mu.Unlock()
+
+		return nil
+	}
+
+	c.closed = true
+	close(c.done)
+
+	conn := c.conn
+	c.conn = nil
+	c.setState(StateClosed, nil)
+
+	c.mu.Unlock()
+
+	if conn != nil {
+		return conn.Close()
+	}
+
+	return nil
+}
+
+// connect returns the current connection or dials a new one if there is no
+// connection or it is broken.
+func (c *Client) connect(ctx context.Context) (*Conn, error) {
+	conn, closed := c.current()
+	if closed {
+		return nil, ErrClientClosed
+	}
+
+	if conn != nil {
+		return conn, nil
+	}
+
+	select {
+	case c.dialLock <- struct{}{}:
+		defer func() { <-c.dialLock }()
+	case <-ctx.Done():
+		return nil, fmt.Errorf("rcon: %w", ctx.Err())
+	case <-c.done:
+		return nil, ErrClientClosed
+	}
+
+	// The connection may have been dialed by another goroutine meanwhile.
+	if conn, closed = c.current(); closed {
+		return nil, ErrClientClosed
+	}
+
+	if conn != nil {
+		return conn, nil
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	go func() {
+		select {
+		case <-c.done:
+			cancel()
+		case <-ctx.Done():
+		}
+	}()
+
+	return c.dial(ctx)
+}
+
+// current returns the current connection, which is nil if there is no
+// connection, and reports whether Client is closed. The broken connection
+// is closed, for example after it was closed by the server or by a failed
+// heartbeat while idle.
+func (c *Client) current() (*Conn, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.closed || c.conn == nil {
+		return nil, c.closed
+	}
+
+	if err := c.conn.failure(); err != nil {
+		_ = c.conn.Close()
+		c.conn = nil
+		c.setState(StateDisconnected, err)
+
+		return nil, false
+	}
+
+	return c.conn, false
+}
+
+// dial dials the server with backoff until it succeeds, attempts are
+// exhausted or ctx is done. The dialLock must be held, the ctx is cancelled
+// by Close.
+func (c *Client) dial(ctx context.Context) (*Conn, error) {
+	for attempt := 0; ; attempt++ {
+		conn, err := c.attempt(ctx)
+		if err == nil || errors.Is(err, ErrClientClosed) || errors.Is(err, ErrAuthFailed) ||
+			errors.Is(err, ErrCircuitOpen) || ctx.Err() != nil ||
+			(c.settings.maxAttempts > 0 && attempt+1 >= c.settings.maxAttempts) {
+			return conn, err
+		}
+
+		timer := time.NewTimer(c.backoff(attempt))
+
+		select {
+		case <-timer.C:
+		case <-ctx.Done():
+			timer.Stop()
+
+			if c.isClosed() {
+				return nil, ErrClientClosed
+			}
+
+			return nil, fmt.Errorf("rcon: %w", ctx.Err())
+		}
+	}
+}
+
+// attempt makes a single dial attempt and publishes its result. The dial
+// runs without c.mu, so State and Close are not blocked by it.
+func (c *Client) attempt(ctx context.Context) (*Conn, error) {
+	c.mu.Lock()
+
+	if c.closed {
+		c.mu.Unlock()
+
+		return nil, ErrClientClosed
+	}
+
+	c.setState(StateConnecting, nil)
+	reconnect := c.dialed
+	c.dialed = true
+
+	c.mu.Unlock()
+
+	if reconnect {
+		connSettings(c.settings.options).metrics.ObserveReconnect(c.address)
+	}
+
+	conn, err := DialContext(ctx, c.address, c.password, c.settings.options...)
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.closed {
+		if conn != nil {
+			_ = conn.Close()
+		}
+
+		return nil, ErrClientClosed
+	}
+
+	if err != nil {
+		c.setState(StateDisconnected, err)
+
+		return nil, err
+	}
+
+	c.conn = conn
+	c.setState(StateConnected, nil)
+
+	return conn, nil
+}
+
+// isClosed reports whether Close was called.
+func (c *Client) isClosed() bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return c.closed
+}
+
+// disconnect closes the broken connection unless it was already replaced.
+func (c *Client) disconnect(conn *Conn, err error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	_ = conn.Close()
+
+	if c.conn == conn {
+		c.conn = nil
+		c.setState(StateDisconnected, err)
+	}
+}
+
+// setState changes the state and calls the state handler. c.mu must be held.
+func (c *Client) setState(state ClientState, err error) {
+	c.state = state
+
+	if c.settings.stateHandler != nil {
+		c.settings.stateHandler(state, err)
+	}
+}
+
+// backoff returns the delay before the next dial attempt.
+func (c *Client) backoff(attempt int) time.Duration {
+	return backoff(c.settings.minBackoff, c.settings.maxBackoff, attempt)
+}
+
+// backoff returns the exponential delay with equal jitter after attempt.
+func backoff(minDelay time.Duration, maxDelay time.Duration, attempt int) time.Duration {
+	delay := minDelay << min(attempt, 30) //nolint:gosec // attempt is not negative
+	if delay > maxDelay || delay <= 0 {
+		delay = maxDelay
+	}
+
+	if delay <= 1 {
+		return delay
+	}
+
+	return delay/2 + rand.N(delay/2) //nolint:gosec // Jitter doesn't need crypto rand
+}
+
+// connSettings returns Settings of Conn options.
+func connSettings(options []Option) Settings {
+	settings := DefaultSettings
+	for _, option := range options {
+		option(&settings)
+	}
+
+	return settings
+}
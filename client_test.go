@@ -0,0 +1,276 @@
+package rcon_test
+
+import (
+	"errors"
+	"net"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/gorcon/rcon"
+	"github.com/gorcon/rcon/rcontest"
+)
+
+func TestClient_Execute(t *testing.T) {
+	var flaky atomic.Int32
+
+	server := rcontest.NewServer(
+		rcontest.SetSettings(rcontest.Settings{Password: "password"}),
+		rcontest.SetCommandHandler(func(c *rcontest.Context) {
+			switch c.Request().Body() {
+			case "crash":
+				// Simulate server restart.
+				c.Conn().Close()
+			case "bye":
+				// Respond and drop the connection like idle timeout does.
+				rcon.NewPacket(rcon.SERVERDATA_RESPONSE_VALUE, c.Request().ID, "bye").WriteTo(c.Conn())
+				c.Conn().Close()
+			case "flaky":
+				if flaky.Add(1) == 1 {
+					c.Conn().Close()
+
+					return
+				}
+
+				rcon.NewPacket(rcon.SERVERDATA_RESPONSE_VALUE, c.Request().ID, "flaky").WriteTo(c.Conn())
+			default:
+				commandHandler(c)
+			}
+		}),
+	)
+	defer server.Close()
+
+	t.Run("reconnect after broken connection", func(t *testing.T) {
+		var (
+			mu     sync.Mutex
+			states []rcon.ClientState
+		)
+
+		client := rcon.NewClient(server.Addr(), "password",
+			rcon.SetReconnectBackoff(time.Millisecond, 10*time.Millisecond),
+			rcon.SetStateHandler(func(state rcon.ClientState, err error) {
+				mu.Lock()
+				states = append(states, state)
+				mu.Unlock()
+			}),
+		)
+		defer client.Close()
+
+		if _, err := client.Execute("crash"); err == nil {
+			t.Fatal("got nil err, want connection error")
+		}
+
+		if client.State() != rcon.StateDisconnected {
+			t.Errorf("got state %s, want %s", client.State(), rcon.StateDisconnected)
+		}
+
+		result, err := client.Execute("help")
+		if err != nil {
+			t.Fatalf("got err %q, want %v", err, nil)
+		}
+
+		if resultWant := "lorem ipsum dolor sit amet"; result != resultWant {
+			t.Fatalf("got result %q, want %q", result, resultWant)
+		}
+
+		mu.Lock()
+		defer mu.Unlock()
+
+		statesWant := []rcon.ClientState{
+			rcon.StateConnecting, rcon.StateConnected, rcon.StateDisconnected,
+			rcon.StateConnecting, rcon.StateConnected,
+		}
+		if len(states) != len(statesWant) {
+			t.Fatalf("got states %v, want %v", states, statesWant)
+		}
+
+		for i := range states {
+			if states[i] != statesWant[i] {
+				t.Fatalf("got states %v, want %v", states, statesWant)
+			}
+		}
+	})
+
+	t.Run("reconnect after connection broken between commands", func(t *testing.T) {
+		var (
+			mu     sync.Mutex
+			states []rcon.ClientState
+		)
+
+		client := rcon.NewClient(server.Addr(), "password",
+			rcon.SetReconnectBackoff(time.Millisecond, 10*time.Millisecond),
+			rcon.SetStateHandler(func(state rcon.ClientState, err error) {
+				mu.Lock()
+				states = append(states, state)
+				mu.Unlock()
+			}),
+		)
+		defer client.Close()
+
+		if _, err := client.Execute("bye"); err != nil {
+			t.Fatalf("got err %q, want %v", err, nil)
+		}
+
+		// Wait for the connection to be closed by the server.
+		time.Sleep(100 * time.Millisecond)
+
+		result, err := client.Execute("help")
+		if err != nil {
+			t.Fatalf("got err %q, want %v", err, nil)
+		}
+
+		if resultWant := "lorem ipsum dolor sit amet"; result != resultWant {
+			t.Fatalf("got result %q, want %q", result, resultWant)
+		}
+
+		mu.Lock()
+		defer mu.Unlock()
+
+		statesWant := []rcon.ClientState{
+			rcon.StateConnecting, rcon.StateConnected, rcon.StateDisconnected,
+			rcon.StateConnecting, rcon.StateConnected,
+		}
+		if len(states) != len(statesWant) {
+			t.Fatalf("got states %v, want %v", states, statesWant)
+		}
+
+		for i := range states {
+			if states[i] != statesWant[i] {
+				t.Fatalf("got states %v, want %v", states, statesWant)
+			}
+		}
+	})
+
+	t.Run("replay idempotent command", func(t *testing.T) {
+		client := rcon.NewClient(server.Addr(), "password",
+			rcon.SetReconnectBackoff(time.Millisecond, 10*time.Millisecond),
+			rcon.SetIdempotent(func(command string) bool { return command == "flaky" }),
+		)
+		defer client.Close()
+
+		result, err := client.Execute("flaky")
+		if err != nil {
+			t.Fatalf("got err %q, want %v", err, nil)
+		}
+
+		if result != "flaky" {
+			t.Fatalf("got result %q, want %q", result, "flaky")
+		}
+	})
+
+	t.Run("max reconnect attempts", func(t *testing.T) {
+		client := rcon.NewClient("127.0.0.2:12345", "password",
+			rcon.SetReconnectBackoff(time.Millisecond, time.Millisecond),
+			rcon.SetMaxReconnectAttempts(3),
+		)
+		defer client.Close()
+
+		if _, err := client.Execute("help"); err == nil {
+			t.Fatal("got nil err, want dial error")
+		}
+	})
+
+	t.Run("authentication failed", func(t *testing.T) {
+		client := rcon.NewClient(server.Addr(), "wrong")
+		defer client.Close()
+
+		if _, err := client.Execute("help"); !errors.Is(err, rcon.ErrAuthFailed) {
+			t.Errorf("got err %q, want %q", err, rcon.ErrAuthFailed)
+		}
+	})
+
+	t.Run("closed", func(t *testing.T) {
+		client := rcon.NewClient(server.Addr(), "password")
+		client.Close()
+
+		if _, err := client.Execute("help"); !errors.Is(err, rcon.ErrClientClosed) {
+			t.Errorf("got err %q, want %q", err, rcon.ErrClientClosed)
+		}
+	})
+}
+
+func TestClient_Close(t *testing.T) {
+	// closeWhileConnecting closes the client while a command is reconnecting
+	// to the address and checks that neither of them blocks.
+	closeWhileConnecting := func(t *testing.T, address string) {
+		t.Helper()
+
+		client := rcon.NewClient(address, "password")
+
+		executed := make(chan error, 1)
+
+		go func() {
+			_, err := client.Execute("status")
+			executed <- err
+		}()
+
+		time.Sleep(100 * time.Millisecond)
+
+		if client.State() != rcon.StateConnecting && client.State() != rcon.StateDisconnected {
+			t.Errorf("got state %s, want reconnecting", client.State())
+		}
+
+		closed := make(chan error, 1)
+
+		go func() {
+			closed <- client.Close()
+		}()
+
+		select {
+		case err := <-closed:
+			if err != nil {
+				t.Errorf("got err %q, want %v", err, nil)
+			}
+		case <-time.After(time.Second):
+			t.Fatal("Close is blocked by reconnect")
+		}
+
+		select {
+		case err := <-executed:
+			if !errors.Is(err, rcon.ErrClientClosed) {
+				t.Errorf("got err %q, want %q", err, rcon.ErrClientClosed)
+			}
+		case <-time.After(time.Second):
+			t.Fatal("Execute is not aborted by Close")
+		}
+
+		if client.State() != rcon.StateClosed {
+			t.Errorf("got state %s, want %s", client.State(), rcon.StateClosed)
+		}
+	}
+
+	t.Run("during backoff", func(t *testing.T) {
+		listener, err := net.Listen("tcp", "127.0.0.1:0")
+		if err != nil {
+			t.Fatalf("got err %q, want %v", err, nil)
+		}
+
+		// Nothing listens on the address, so dial attempts fail.
+		address := listener.Addr().String()
+		listener.Close()
+
+		closeWhileConnecting(t, address)
+	})
+
+	t.Run("during dial", func(t *testing.T) {
+		listener, err := net.Listen("tcp", "127.0.0.1:0")
+		if err != nil {
+			t.Fatalf("got err %q, want %v", err, nil)
+		}
+		defer listener.Close()
+
+		// Connections are accepted, but auth is never answered.
+		go func() {
+			for {
+				conn, err := listener.Accept()
+				if err != nil {
+					return
+				}
+				defer conn.Close()
+			}
+		}()
+
+		closeWhileConnecting(t, listener.Addr().String())
+	})
+}
@@ -0,0 +1,270 @@
+package rcon
+
+import (
+	"fmt"
+	"slices"
+	"sync"
+)
+
+// PacketAction tells Conn how to handle a packet received from the server.
+type PacketAction int
+
+const (
+	// PacketAccept dispatches the packet by its ID.
+	PacketAccept PacketAction = iota
+
+	// PacketSkip drops the packet instead of passing it to a request.
+	// Packets with IDs which are never allocated by Conn, like -1, are
+	// still delivered to subscriptions.
+	PacketSkip
+
+	// PacketAssign dispatches the packet to the oldest pending request
+	// regardless of its ID.
+	PacketAssign
+)
+
+// Dialect describes the behaviour of a particular game server RCON
+// implementation, which deviates from Source RCON Protocol in some way.
+// Custom dialects can embed one of the built-in dialects and override
+// only the methods they need.
+type Dialect interface {
+	// Name returns the dialect name used in the registry.
+	Name() string
+
+	// MaxCommandLen returns the maximum command length accepted by the
+	// server. Zero means no limit.
+	MaxCommandLen() int
+
+	// MultiPacket returns the strategy of reading responses split into
+	// multiple packets.
+	MultiPacket() MultiPacketStrategy
+
+	// HeartbeatCommand returns the harmless command sent to check the
+	// connection is alive. Empty string means an empty
+	// SERVERDATA_RESPONSE_VALUE packet, which is answered by Source and
+	// Minecraft servers.
+	HeartbeatCommand() string
+
+	// SkipAuthPacket reports whether the packet received in response to
+	// SERVERDATA_AUTH request precedes SERVERDATA_AUTH_RESPONSE and must be
+	// skipped. Only the packet header is read at this moment, so the packet
+	// has no body.
+	SkipAuthPacket(packet *Packet) bool
+
+	// HandlePacket returns the action for the packet received after
+	// authentication. The previous is the packet received before it or nil.
+	HandlePacket(packet *Packet, previous *Packet) PacketAction
+}
+
+// Built-in dialects.
+var (
+	// DialectGeneric is the default dialect, which keeps the behaviour of the
+	// previous versions. It tolerates the missing empty SERVERDATA_RESPONSE_VALUE
+	// packet on auth and Rust type 4 packets.
+	DialectGeneric Dialect = dialect{
+		name:          "generic",
+		maxCommandLen: DefaultMaxCommandLen,
+		multiPacket:   MultiPacketNone,
+		handle:        handleRust,
+	}
+
+	// DialectSource is the dialect of Source Dedicated Server games like
+	// Counter-Strike, Team Fortress 2 and Left 4 Dead 2.
+	DialectSource Dialect = dialect{
+		name:          "source",
+		maxCommandLen: DefaultMaxCommandLen,
+		multiPacket:   MultiPacketMirror,
+	}
+
+	// DialectMinecraft is the dialect of Minecraft Java Edition. It rejects
+	// request bodies longer than 1446 bytes and splits responses into 4096
+	// bytes packets without any terminator, so they are reassembled with
+	// MultiPacketSentinel strategy.
+	DialectMinecraft Dialect = dialect{
+		name:          "minecraft",
+		maxCommandLen: 1446,
+		multiPacket:   MultiPacketSentinel,
+	}
+
+	// DialectRust is the dialect of Rust (with +rcon.web 0). Responses are
+	// preceded by an undocumented packet with type 4, and some commands
+	// respond with console message with ID -1 instead of the request ID.
+	DialectRust Dialect = dialect{
+		name:          "rust",
+		maxCommandLen: DefaultMaxCommandLen,
+		multiPacket:   MultiPacketNone,
+		heartbeat:     "serverinfo",
+		handle:        handleRust,
+	}
+
+	// DialectARK is the dialect of ARK: Survival Evolved and Ascended.
+	DialectARK Dialect = dialect{
+		name:          "ark",
+		maxCommandLen: DefaultMaxCommandLen,
+		multiPacket:   MultiPacketNone,
+		heartbeat:     "ListPlayers",
+	}
+
+	// DialectSquad is the dialect of Squad. Chat messages are pushed as
+	// unsolicited packets, which can be received with Conn.Subscribe.
+	DialectSquad Dialect = dialect{
+		name:          "squad",
+		maxCommandLen: DefaultMaxCommandLen,
+		multiPacket:   MultiPacketMirror,
+	}
+
+	// DialectFactorio is the dialect of Factorio.
+	DialectFactorio Dialect = dialect{
+		name:          "factorio",
+		maxCommandLen: 0,
+		multiPacket:   MultiPacketNone,
+		heartbeat:     "/version",
+	}
+
+	// DialectPalworld is the dialect of Palworld.
+	DialectPalworld Dialect = dialect{
+		name:          "palworld",
+		maxCommandLen: DefaultMaxCommandLen,
+		multiPacket:   MultiPacketNone,
+		heartbeat:     "Info",
+	}
+
+	// DialectConanExiles is the dialect of Conan Exiles.
+	DialectConanExiles Dialect = dialect{
+		name:          "conanexiles",
+		maxCommandLen: DefaultMaxCommandLen,
+		multiPacket:   MultiPacketNone,
+		heartbeat:     "listplayers",
+	}
+
+	// DialectSpaceEngineers is the dialect of Space Engineers RCON plugins.
+	DialectSpaceEngineers Dialect = dialect{
+		name:          "spaceengineers",
+		maxCommandLen: DefaultMaxCommandLen,
+		multiPacket:   MultiPacketNone,
+	}
+)
+
+// dialects is the registry of dialects by name.
+var dialects = struct {
+	sync.RWMutex
+	m map[string]Dialect
+}{m: make(map[string]Dialect)}
+
+func init() {
+	for _, d := range []Dialect{
+		DialectGeneric, DialectSource, DialectMinecraft, DialectRust, DialectARK, DialectSquad,
+		DialectFactorio, DialectPalworld, DialectConanExiles, DialectSpaceEngineers,
+	} {
+		RegisterDialect(d)
+	}
+}
+
+// RegisterDialect makes the dialect available by its name in LookupDialect.
+// If RegisterDialect is called twice with the same name or if dialect is nil,
+// it panics.
+func RegisterDialect(d Dialect) {
+	if d == nil {
+		panic("rcon: register dialect is nil")
+	}
+
+	dialects.Lock()
+	defer dialects.Unlock()
+
+	if _, dup := dialects.m[d.Name()]; dup {
+		panic(fmt.Sprintf("rcon: register called twice for dialect %q", d.Name()))
+	}
+
+	dialects.m[d.Name()] = d
+}
+
+// LookupDialect returns the registered dialect by its name.
+func LookupDialect(name string) (Dialect, bool) {
+	dialects.RLock()
+	defer dialects.RUnlock()
+
+	d, ok := dialects.m[name]
+
+	return d, ok
+}
+
+// Dialects returns a sorted list of the names of the registered dialects.
+func Dialects() []string {
+	dialects.RLock()
+	defer dialects.RUnlock()
+
+	names := make([]string, 0, len(dialects.m))
+	for name := range dialects.m {
+		names = append(names, name)
+	}
+
+	slices.Sort(names)
+
+	return names
+}
+
+// dialect is a built-in Dialect.
+type dialect struct {
+	name          string
+	maxCommandLen int
+	multiPacket   MultiPacketStrategy
+	heartbeat     string
+	handle        func(packet *Packet, previous *Packet) PacketAction
+}
+
+// Name implements Dialect.
+func (d dialect) Name() string {
+	return d.name
+}
+
+// MaxCommandLen implements Dialect.
+func (d dialect) MaxCommandLen() int {
+	return d.maxCommandLen
+}
+
+// MultiPacket implements Dialect.
+func (d dialect) MultiPacket() MultiPacketStrategy {
+	return d.multiPacket
+}
+
+// HeartbeatCommand implements Dialect.
+func (d dialect) HeartbeatCommand() string {
+	return d.heartbeat
+}
+
+// SkipAuthPacket implements Dialect. When the server receives an auth
+// request, it will respond with an empty SERVERDATA_RESPONSE_VALUE, followed
+// immediately by a SERVERDATA_AUTH_RESPONSE indicating whether authentication
+// succeeded or failed. Some servers doesn't send an empty
+// SERVERDATA_RESPONSE_VALUE packet, so it is skipped only if present.
+func (d dialect) SkipAuthPacket(packet *Packet) bool {
+	return packet.Type == SERVERDATA_RESPONSE_VALUE
+}
+
+// HandlePacket implements Dialect.
+func (d dialect) HandlePacket(packet *Packet, previous *Packet) PacketAction {
+	if d.handle == nil {
+		return PacketAccept
+	}
+
+	return d.handle(packet, previous)
+}
+
+// handleRust handles Rust packets.
+func handleRust(packet *Packet, previous *Packet) PacketAction {
+	// Rust rcon server responses packet with a type of 4 and the next packet
+	// is valid. It is undocumented, so skip the packet.
+	if packet.Type == 4 {
+		return PacketSkip
+	}
+
+	// When sent command "Say" there is no response data from server with
+	// the request packet ID, only previous console message that command
+	// was received with packet.ID = -1, therefore, forcibly pass it to the
+	// request.
+	if previous != nil && previous.Type == 4 && packet.ID == -1 {
+		return PacketAssign
+	}
+
+	return PacketAccept
+}
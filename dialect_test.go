@@ -0,0 +1,213 @@
+package rcon_test
+
+import (
+	"errors"
+	"slices"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/gorcon/rcon"
+	"github.com/gorcon/rcon/rcontest"
+)
+
+// noiseDialect skips packets with type 5 sent by the test server.
+type noiseDialect struct {
+	rcon.Dialect
+}
+
+func (d noiseDialect) Name() string {
+	return "noise"
+}
+
+func (d noiseDialect) HandlePacket(packet *rcon.Packet, previous *rcon.Packet) rcon.PacketAction {
+	if packet.Type == 5 {
+		return rcon.PacketSkip
+	}
+
+	return d.Dialect.HandlePacket(packet, previous)
+}
+
+func TestRegisterDialect(t *testing.T) {
+	t.Run("built-in dialects", func(t *testing.T) {
+		for _, name := range []string{
+			"generic", "source", "minecraft", "rust", "ark", "squad",
+			"factorio", "palworld", "conanexiles", "spaceengineers",
+		} {
+			d, ok := rcon.LookupDialect(name)
+			if !ok || d.Name() != name {
+				t.Errorf("got dialect %v, want %q", d, name)
+			}
+		}
+	})
+
+	t.Run("custom dialect", func(t *testing.T) {
+		if _, ok := rcon.LookupDialect("noise"); !ok {
+			rcon.RegisterDialect(noiseDialect{Dialect: rcon.DialectGeneric})
+		}
+
+		if !slices.Contains(rcon.Dialects(), "noise") {
+			t.Errorf("got dialects %q, want to contain %q", rcon.Dialects(), "noise")
+		}
+	})
+
+	t.Run("duplicate", func(t *testing.T) {
+		defer func() {
+			if recover() == nil {
+				t.Error("got no panic, want panic")
+			}
+		}()
+
+		rcon.RegisterDialect(rcon.DialectSource)
+	})
+}
+
+func TestSetDialect(t *testing.T) {
+	server := rcontest.NewServer(
+		rcontest.SetSettings(rcontest.Settings{Password: "password"}),
+		rcontest.SetCommandHandler(func(c *rcontest.Context) {
+			switch c.Request().Body() {
+			case "noise":
+				rcon.NewPacket(5, c.Request().ID, "noise").WriteTo(c.Conn())
+				rcon.NewPacket(rcon.SERVERDATA_RESPONSE_VALUE, c.Request().ID, "signal").WriteTo(c.Conn())
+			case "long":
+				c.WriteResponse(strings.Repeat("a", 5000))
+			default:
+				commandHandler(c)
+			}
+		}),
+	)
+	defer server.Close()
+
+	t.Run("custom dialect", func(t *testing.T) {
+		conn, err := rcon.Dial(server.Addr(), "password", rcon.SetDialect(noiseDialect{Dialect: rcon.DialectGeneric}))
+		if err != nil {
+			t.Fatalf("got err %q, want %v", err, nil)
+		}
+		defer conn.Close()
+
+		result, err := conn.Execute("noise")
+		if err != nil {
+			t.Fatalf("got err %q, want %v", err, nil)
+		}
+
+		if result != "signal" {
+			t.Fatalf("got result %q, want %q", result, "signal")
+		}
+	})
+
+	t.Run("rust", func(t *testing.T) {
+		conn, err := rcon.Dial(server.Addr(), "password", rcon.SetDialect(rcon.DialectRust))
+		if err != nil {
+			t.Fatalf("got err %q, want %v", err, nil)
+		}
+		defer conn.Close()
+
+		result, err := conn.Execute("rust")
+		if err != nil {
+			t.Fatalf("got err %q, want %v", err, nil)
+		}
+
+		if result != "rust" {
+			t.Fatalf("got result %q, want %q", result, "rust")
+		}
+	})
+
+	t.Run("source multi packet", func(t *testing.T) {
+		conn, err := rcon.Dial(server.Addr(), "password", rcon.SetDialect(rcon.DialectSource))
+		if err != nil {
+			t.Fatalf("got err %q, want %v", err, nil)
+		}
+		defer conn.Close()
+
+		result, err := conn.Execute("long")
+		if err != nil {
+			t.Fatalf("got err %q, want %v", err, nil)
+		}
+
+		if len(result) != 5000 {
+			t.Fatalf("got result len %d, want %d", len(result), 5000)
+		}
+	})
+
+	t.Run("minecraft command too long", func(t *testing.T) {
+		conn, err := rcon.Dial(server.Addr(), "password", rcon.SetDialect(rcon.DialectMinecraft))
+		if err != nil {
+			t.Fatalf("got err %q, want %v", err, nil)
+		}
+		defer conn.Close()
+
+		if _, err := conn.Execute(strings.Repeat("a", 1447)); !errors.Is(err, rcon.ErrCommandTooLong) {
+			t.Errorf("got err %q, want %q", err, rcon.ErrCommandTooLong)
+		}
+
+		if _, err := conn.Execute(strings.Repeat("a", 1446)); err != nil {
+			t.Errorf("got err %q, want %v", err, nil)
+		}
+	})
+}
+
+func TestDialectMinecraft(t *testing.T) {
+	server := rcontest.NewMinecraftServer(
+		rcontest.SetSettings(rcontest.Settings{Password: "password"}),
+		rcontest.SetCommandHandler(func(c *rcontest.Context) {
+			switch c.Request().Body() {
+			case "long":
+				c.WriteResponse(strings.Repeat("a", 10000))
+			default:
+				c.WriteResponse(c.Request().Body())
+			}
+		}),
+	)
+	defer server.Close()
+
+	conn, err := rcon.Dial(server.Addr(), "password", rcon.SetDialect(rcon.DialectMinecraft))
+	if err != nil {
+		t.Fatalf("got err %q, want %v", err, nil)
+	}
+	defer conn.Close()
+
+	t.Run("fragmented response", func(t *testing.T) {
+		result, err := conn.Execute("long")
+		if err != nil {
+			t.Fatalf("got err %q, want %v", err, nil)
+		}
+
+		if result != strings.Repeat("a", 10000) {
+			t.Fatalf("got result len %d, want %d", len(result), 10000)
+		}
+	})
+
+	t.Run("concurrent", func(t *testing.T) {
+		var wg sync.WaitGroup
+
+		for _, command := range []string{"one", "two", "three", "long"} {
+			wg.Add(1)
+
+			go func() {
+				defer wg.Done()
+
+				result, err := conn.Execute(command)
+				if err != nil {
+					t.Errorf("got err %q, want %v", err, nil)
+				}
+
+				if command != "long" && result != command {
+					t.Errorf("got result %q, want %q", result, command)
+				}
+			}()
+		}
+
+		wg.Wait()
+	})
+
+	t.Run("command too long", func(t *testing.T) {
+		if _, err := conn.Execute(strings.Repeat("a", 1447)); !errors.Is(err, rcon.ErrCommandTooLong) {
+			t.Errorf("got err %q, want %q", err, rcon.ErrCommandTooLong)
+		}
+
+		if result, err := conn.Execute(strings.Repeat("a", 1446)); err != nil || len(result) != 1446 {
+			t.Errorf("got result len %d and err %v, want %d and %v", len(result), err, 1446, nil)
+		}
+	})
+}
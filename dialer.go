@@ -0,0 +1,26 @@
+package rcon
+
+import (
+	"context"
+	"net"
+)
+
+// Dialer connects to the server. It is satisfied by *net.Dialer,
+// golang.org/x/net/proxy dialers and the built-in SOCKS5Dialer and
+// HTTPProxyDialer, so the connection can be routed through a proxy, bound to
+// a local address or configured with TCP keepalive.
+type Dialer interface {
+	DialContext(ctx context.Context, network string, address string) (net.Conn, error)
+}
+
+// dial connects to address with the dialer from settings or with net.Dialer
+// if it is not set. The ctx carries the dial timeout.
+func dial(ctx context.Context, address string, settings Settings) (net.Conn, error) {
+	if settings.dialer == nil {
+		var dialer net.Dialer
+
+		return dialer.DialContext(ctx, "tcp", address)
+	}
+
+	return settings.dialer.DialContext(ctx, "tcp", address)
+}
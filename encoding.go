@@ -0,0 +1,155 @@
+package rcon
+
+import "unicode/utf8"
+
+// Encoding transcodes command and response bodies between Go strings, which
+// are UTF-8, and the character encoding used by the server.
+type Encoding interface {
+	// Name returns the encoding name.
+	Name() string
+
+	// Encode converts the UTF-8 string to the server encoding. Characters
+	// which can not be represented are replaced with '?'.
+	Encode(s string) []byte
+
+	// Decode converts bytes in the server encoding to the UTF-8 string.
+	Decode(b []byte) string
+}
+
+// Built-in encodings.
+var (
+	// EncodingUTF8 is the default encoding, which passes bodies as is.
+	EncodingUTF8 Encoding = utf8Encoding{}
+
+	// EncodingLatin1 is ISO 8859-1 encoding.
+	EncodingLatin1 Encoding = newCharmap("iso-8859-1", latin1High())
+
+	// EncodingCP1251 is Windows-1251 encoding, used for Cyrillic.
+	EncodingCP1251 Encoding = newCharmap("windows-1251", cp1251High())
+
+	// EncodingCP1252 is Windows-1252 encoding, used for Western European
+	// languages. It is Latin-1 with printable characters instead of C1
+	// control codes.
+	EncodingCP1252 Encoding = newCharmap("windows-1252", cp1252High())
+)
+
+// utf8Encoding is Encoding of UTF-8 bodies.
+type utf8Encoding struct{}
+
+// Name implements Encoding.
+func (utf8Encoding) Name() string {
+	return "utf-8"
+}
+
+// Encode implements Encoding.
+func (utf8Encoding) Encode(s string) []byte {
+	return []byte(s)
+}
+
+// Decode implements Encoding. Invalid sequences are kept as is, like the
+// string conversion does.
+func (utf8Encoding) Decode(b []byte) string {
+	return string(b)
+}
+
+// charmap is Encoding of a single byte character set, which is ASCII
+// compatible in the lower half.
+type charmap struct {
+	name    string
+	high    [128]rune
+	reverse map[rune]byte
+}
+
+// newCharmap creates charmap with runes of bytes 0x80-0xFF.
+func newCharmap(name string, high [128]rune) *charmap {
+	reverse := make(map[rune]byte, len(high))
+
+	for i, r := range high {
+		reverse[r] = byte(0x80 + i)
+	}
+
+	return &charmap{name: name, high: high, reverse: reverse}
+}
+
+// Name implements Encoding.
+func (c *charmap) Name() string {
+	return c.name
+}
+
+// Encode implements Encoding.
+func (c *charmap) Encode(s string) []byte {
+	b := make([]byte, 0, len(s))
+
+	for _, r := range s {
+		switch char, ok := c.reverse[r]; {
+		case r < utf8.RuneSelf:
+			b = append(b, byte(r))
+		case ok:
+			b = append(b, char)
+		default:
+			b = append(b, '?')
+		}
+	}
+
+	return b
+}
+
+// Decode implements Encoding.
+func (c *charmap) Decode(b []byte) string {
+	s := make([]byte, 0, len(b))
+
+	for _, char := range b {
+		if char < utf8.RuneSelf {
+			s = append(s, char)
+		} else {
+			s = utf8.AppendRune(s, c.high[char-0x80])
+		}
+	}
+
+	return string(s)
+}
+
+// latin1High returns ISO 8859-1 runes of bytes 0x80-0xFF, which are equal
+// to the byte values.
+func latin1High() [128]rune {
+	var high [128]rune
+
+	for i := range high {
+		high[i] = rune(0x80 + i)
+	}
+
+	return high
+}
+
+// cp1251High returns Windows-1251 runes of bytes 0x80-0xFF. The undefined
+// byte 0x98 is mapped to the C1 control code like WHATWG Encoding Standard
+// does.
+func cp1251High() [128]rune {
+	high := [128]rune{
+		'Ђ', 'Ѓ', '‚', 'ѓ', '„', '…', '†', '‡', '€', '‰', 'Љ', '‹', 'Њ', 'Ќ', 'Ћ', 'Џ',
+		'ђ', '‘', '’', '“', '”', '•', '–', '—', 0x98, '™', 'љ', '›', 'њ', 'ќ', 'ћ', 'џ',
+		0xA0, 'Ў', 'ў', 'Ј', '¤', 'Ґ', '¦', '§', 'Ё', '©', 'Є', '«', '¬', 0xAD, '®', 'Ї',
+		'°', '±', 'І', 'і', 'ґ', 'µ', '¶', '·', 'ё', '№', 'є', '»', 'ј', 'Ѕ', 'ѕ', 'ї',
+	}
+
+	// Bytes 0xC0-0xFF are А-я in alphabetical order.
+	for i := 0x40; i < len(high); i++ {
+		high[i] = rune('А' + i - 0x40)
+	}
+
+	return high
+}
+
+// cp1252High returns Windows-1252 runes of bytes 0x80-0xFF. The undefined
+// bytes 0x81, 0x8D, 0x8F, 0x90 and 0x9D are mapped to the C1 control codes
+// like WHATWG Encoding Standard does.
+func cp1252High() [128]rune {
+	high := latin1High()
+
+	copy(high[:], []rune{
+		'€', 0x81, '‚', 'ƒ', '„', '…', '†', '‡', 'ˆ', '‰', 'Š', '‹', 'Œ', 0x8D, 'Ž', 0x8F,
+		0x90, '‘', '’', '“', '”', '•', '–', '—', '˜', '™', 'š', '›', 'œ', 0x9D, 'ž', 'Ÿ',
+	})
+
+	return high
+}
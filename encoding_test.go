@@ -0,0 +1,132 @@
+package rcon_test
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/gorcon/rcon"
+	"github.com/gorcon/rcon/rcontest"
+)
+
+func TestEncoding(t *testing.T) {
+	tests := []struct {
+		encoding rcon.Encoding
+		text     string
+		encoded  []byte
+	}{
+		{rcon.EncodingUTF8, "Привет", []byte("Привет")},
+		{rcon.EncodingLatin1, "Größe ÿ", []byte{'G', 'r', 0xF6, 0xDF, 'e', ' ', 0xFF}},
+		{rcon.EncodingCP1251, "Привет, Ёж №1", []byte{
+			0xCF, 0xF0, 0xE8, 0xE2, 0xE5, 0xF2, ',', ' ', 0xA8, 0xE6, ' ', 0xB9, '1',
+		}},
+		{rcon.EncodingCP1252, "€5 Œuvre — Ÿ", []byte{0x80, '5', ' ', 0x8C, 'u', 'v', 'r', 'e', ' ', 0x97, ' ', 0x9F}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.encoding.Name(), func(t *testing.T) {
+			if got := tt.encoding.Encode(tt.text); !bytes.Equal(got, tt.encoded) {
+				t.Errorf("got encoded % x, want % x", got, tt.encoded)
+			}
+
+			if got := tt.encoding.Decode(tt.encoded); got != tt.text {
+				t.Errorf("got decoded %q, want %q", got, tt.text)
+			}
+		})
+	}
+
+	t.Run("unknown characters", func(t *testing.T) {
+		if got := rcon.EncodingCP1251.Encode("日本ä"); string(got) != "???" {
+			t.Errorf("got encoded %q, want %q", got, "???")
+		}
+	})
+
+	t.Run("round trip", func(t *testing.T) {
+		for _, encoding := range []rcon.Encoding{rcon.EncodingLatin1, rcon.EncodingCP1251, rcon.EncodingCP1252} {
+			for b := range 256 {
+				if got := encoding.Encode(encoding.Decode([]byte{byte(b)})); !bytes.Equal(got, []byte{byte(b)}) {
+					t.Errorf("%s: got % x for byte %#x", encoding.Name(), got, b)
+				}
+			}
+		}
+	})
+}
+
+func TestSetEncoding(t *testing.T) {
+	var received []byte
+
+	server := rcontest.NewServer(
+		rcontest.SetSettings(rcontest.Settings{Password: "password", MaxResponseBodySize: 3}),
+		rcontest.SetCommandHandler(func(c *rcontest.Context) {
+			received = []byte(c.Request().Body())
+			c.WriteResponse(c.Request().Body())
+		}),
+	)
+	defer server.Close()
+
+	t.Run("cp1251", func(t *testing.T) {
+		conn, err := rcon.Dial(server.Addr(), "password",
+			rcon.SetMultiPacket(rcon.MultiPacketMirror), rcon.SetEncoding(rcon.EncodingCP1251))
+		if err != nil {
+			t.Fatalf("got err %q, want %v", err, nil)
+		}
+		defer conn.Close()
+
+		result, err := conn.Execute("say Привет")
+		if err != nil {
+			t.Fatalf("got err %q, want %v", err, nil)
+		}
+
+		if want := rcon.EncodingCP1251.Encode("say Привет"); !bytes.Equal(received, want) {
+			t.Errorf("got received % x, want % x", received, want)
+		}
+
+		if result != "say Привет" {
+			t.Errorf("got result %q, want %q", result, "say Привет")
+		}
+	})
+
+	t.Run("utf-8 split between packets", func(t *testing.T) {
+		conn, err := rcon.Dial(server.Addr(), "password", rcon.SetMultiPacket(rcon.MultiPacketMirror))
+		if err != nil {
+			t.Fatalf("got err %q, want %v", err, nil)
+		}
+		defer conn.Close()
+
+		// Each Cyrillic letter is 2 bytes, so packets of 3 bytes split them.
+		result, err := conn.Execute("Привет")
+		if err != nil {
+			t.Fatalf("got err %q, want %v", err, nil)
+		}
+
+		if result != "Привет" {
+			t.Errorf("got result %q, want %q", result, "Привет")
+		}
+	})
+
+	t.Run("max command length", func(t *testing.T) {
+		conn, err := rcon.Dial(server.Addr(), "password",
+			rcon.SetMaxCommandLen(6), rcon.SetEncoding(rcon.EncodingCP1251))
+		if err != nil {
+			t.Fatalf("got err %q, want %v", err, nil)
+		}
+		defer conn.Close()
+
+		// 12 bytes in UTF-8, but 6 bytes in Windows-1251.
+		if _, err := conn.Execute("Привет"); err != nil {
+			t.Errorf("got err %q, want %v", err, nil)
+		}
+	})
+	t.Run("password", func(t *testing.T) {
+		server := rcontest.NewServer(rcontest.SetSettings(rcontest.Settings{
+			Password: string(rcon.EncodingCP1251.Encode("пароль")),
+		}))
+		defer server.Close()
+
+		conn, err := rcon.Dial(server.Addr(), "пароль", rcon.SetEncoding(rcon.EncodingCP1251))
+		if err != nil {
+			t.Fatalf("got err %q, want %v", err, nil)
+		}
+
+		conn.Close()
+	})
+}
@@ -0,0 +1,93 @@
+package rcon
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"time"
+)
+
+// ErrHeartbeatFailed is returned by commands executed on Conn which was
+// closed because of heartbeat failure.
+var ErrHeartbeatFailed = errors.New("heartbeat failed")
+
+// heartbeat checks the connection is alive when it is idle for the heartbeat
+// interval, until Conn is stopped.
+func (c *Conn) heartbeat() {
+	defer close(c.heartbeatDone)
+
+	interval := c.settings.heartbeatInterval
+
+	timer := time.NewTimer(interval)
+	defer timer.Stop()
+
+	for {
+		select {
+		case <-c.done:
+			return
+		case <-timer.C:
+		}
+
+		// Any packet received from the server proves the connection is alive.
+		if idle := time.Since(time.Unix(0, c.lastRead.Load())); idle < interval {
+			timer.Reset(interval - idle)
+
+			continue
+		}
+
+		latency, err := c.ping()
+
+		c.settings.debug(context.Background(), "rcon: heartbeat", slog.Duration("latency", latency), errorAttr(err))
+
+		c.mu.Lock()
+		stopped := c.closed || (err == nil && c.err != nil)
+		c.mu.Unlock()
+
+		if stopped {
+			return
+		}
+
+		if err != nil {
+			err = fmt.Errorf("rcon: %w: %w", ErrHeartbeatFailed, err)
+			c.fail(err)
+		}
+
+		if c.settings.heartbeatHandler != nil {
+			c.settings.heartbeatHandler(latency, err)
+		}
+
+		if err != nil {
+			return
+		}
+
+		timer.Reset(interval)
+	}
+}
+
+// ping sends the heartbeat command and returns the round-trip latency.
+func (c *Conn) ping() (time.Duration, error) {
+	ctx := context.Background()
+
+	// Conn read deadline limits the wait, but it may be disabled.
+	timeout := c.settings.deadline
+	if timeout == 0 {
+		timeout = c.settings.heartbeatInterval
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	start := time.Now()
+
+	var err error
+
+	// Heartbeat is not limited by the rate limit.
+	if command := c.settings.heartbeatCommand; command != "" {
+		_, err = c.execute(ctx, SERVERDATA_EXECCOMMAND, string(c.settings.encoding.Encode(command)), c.settings.multiPacket)
+	} else {
+		_, err = c.execute(ctx, SERVERDATA_RESPONSE_VALUE, "", MultiPacketNone)
+	}
+
+	return time.Since(start), err
+}
@@ -0,0 +1,110 @@
+package rcon_test
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/gorcon/rcon"
+	"github.com/gorcon/rcon/rcontest"
+)
+
+type heartbeat struct {
+	latency time.Duration
+	err     error
+}
+
+func TestSetHeartbeat(t *testing.T) {
+	server := rcontest.NewServer(
+		rcontest.SetSettings(rcontest.Settings{Password: "password"}),
+		rcontest.SetCommandHandler(func(c *rcontest.Context) {
+			if c.Request().Body() != "hang" {
+				commandHandler(c)
+			}
+		}),
+	)
+	defer server.Close()
+
+	dial := func(t *testing.T, address string, options ...rcon.Option) (*rcon.Conn, chan heartbeat) {
+		t.Helper()
+
+		heartbeats := make(chan heartbeat, 10)
+
+		options = append(options, rcon.SetHeartbeat(20*time.Millisecond, func(latency time.Duration, err error) {
+			select {
+			case heartbeats <- heartbeat{latency: latency, err: err}:
+			default:
+			}
+		}))
+
+		conn, err := rcon.Dial(address, "password", options...)
+		if err != nil {
+			t.Fatalf("got err %q, want %v", err, nil)
+		}
+
+		return conn, heartbeats
+	}
+
+	receive := func(t *testing.T, heartbeats chan heartbeat) heartbeat {
+		t.Helper()
+
+		select {
+		case hb := <-heartbeats:
+			return hb
+		case <-time.After(time.Second):
+			t.Fatal("got no heartbeat, want one")
+		}
+
+		return heartbeat{}
+	}
+
+	t.Run("empty packet", func(t *testing.T) {
+		conn, heartbeats := dial(t, server.Addr(), rcon.SetDialect(rcon.DialectSource))
+		defer conn.Close()
+
+		if hb := receive(t, heartbeats); hb.err != nil || hb.latency <= 0 {
+			t.Errorf("got heartbeat %+v, want positive latency and no error", hb)
+		}
+
+		if _, err := conn.Execute("help"); err != nil {
+			t.Errorf("got err %q, want %v", err, nil)
+		}
+	})
+
+	t.Run("command", func(t *testing.T) {
+		conn, heartbeats := dial(t, server.Addr(), rcon.SetHeartbeatCommand("help"))
+		defer conn.Close()
+
+		for range 2 {
+			if hb := receive(t, heartbeats); hb.err != nil {
+				t.Errorf("got err %q, want %v", hb.err, nil)
+			}
+		}
+	})
+
+	t.Run("minecraft", func(t *testing.T) {
+		server := rcontest.NewMinecraftServer(rcontest.SetSettings(rcontest.Settings{Password: "password"}))
+		defer server.Close()
+
+		conn, heartbeats := dial(t, server.Addr(), rcon.SetDialect(rcon.DialectMinecraft))
+		defer conn.Close()
+
+		if hb := receive(t, heartbeats); hb.err != nil {
+			t.Errorf("got err %q, want %v", hb.err, nil)
+		}
+	})
+
+	t.Run("failure", func(t *testing.T) {
+		conn, heartbeats := dial(t, server.Addr(),
+			rcon.SetHeartbeatCommand("hang"), rcon.SetDeadline(50*time.Millisecond))
+		defer conn.Close()
+
+		if hb := receive(t, heartbeats); !errors.Is(hb.err, rcon.ErrHeartbeatFailed) {
+			t.Fatalf("got err %q, want %q", hb.err, rcon.ErrHeartbeatFailed)
+		}
+
+		if _, err := conn.Execute("help"); !errors.Is(err, rcon.ErrHeartbeatFailed) {
+			t.Errorf("got err %q, want %q", err, rcon.ErrHeartbeatFailed)
+		}
+	})
+}
@@ -0,0 +1,47 @@
+package rcon
+
+import (
+	"context"
+	"log/slog"
+)
+
+// maxLogBodyLen is the maximum length of packet body written to the log.
+// Longer bodies are truncated.
+const maxLogBodyLen = 256
+
+// redacted replaces the password in SERVERDATA_AUTH packet body.
+const redacted = "[REDACTED]"
+
+// debug writes the event to the logger with debug level if it is set.
+func (s *Settings) debug(ctx context.Context, msg string, attrs ...slog.Attr) {
+	if s.logger == nil || !s.logger.Enabled(ctx, slog.LevelDebug) {
+		return
+	}
+
+	s.logger.LogAttrs(ctx, slog.LevelDebug, msg, attrs...)
+}
+
+// errorAttr returns the error for logging.
+func errorAttr(err error) slog.Attr {
+	return slog.Any("error", err)
+}
+
+// packetAttr returns the packet metadata for logging. The body of
+// SERVERDATA_AUTH packet is always redacted.
+func packetAttr(packet *Packet) slog.Attr {
+	body := packet.Body()
+
+	switch {
+	case packet.Type == SERVERDATA_AUTH:
+		body = redacted
+	case len(body) > maxLogBodyLen:
+		body = body[:maxLogBodyLen] + "..."
+	}
+
+	return slog.Group("packet",
+		slog.Int("size", int(packet.Size)),
+		slog.Int("id", int(packet.ID)),
+		slog.Int("type", int(packet.Type)),
+		slog.String("body", body),
+	)
+}
@@ -0,0 +1,50 @@
+package rcon_test
+
+import (
+	"bytes"
+	"log/slog"
+	"strings"
+	"testing"
+
+	"github.com/gorcon/rcon"
+	"github.com/gorcon/rcon/rcontest"
+)
+
+func TestSetLogger(t *testing.T) {
+	server := rcontest.NewServer(
+		rcontest.SetSettings(rcontest.Settings{Password: "s3cr3t"}),
+		rcontest.SetCommandHandler(commandHandler),
+	)
+	defer server.Close()
+
+	var buffer bytes.Buffer
+
+	logger := slog.New(slog.NewTextHandler(&buffer, &slog.HandlerOptions{Level: slog.LevelDebug}))
+
+	conn, err := rcon.Dial(server.Addr(), "s3cr3t", rcon.SetLogger(logger))
+	if err != nil {
+		t.Fatalf("got err %q, want %v", err, nil)
+	}
+
+	if _, err := conn.Execute("rust"); err != nil {
+		t.Fatalf("got err %q, want %v", err, nil)
+	}
+
+	// Close waits for the reader goroutine, so the buffer can be read.
+	conn.Close()
+
+	log := buffer.String()
+
+	for _, want := range []string{
+		"rcon: dialed", "rcon: authenticated", "rcon: write packet", "rcon: read packet",
+		"rcon: skip packet", "rcon: assign packet", "packet.body=[REDACTED]", "remote_addr=" + server.Addr(),
+	} {
+		if !strings.Contains(log, want) {
+			t.Errorf("got log %q, want to contain %q", log, want)
+		}
+	}
+
+	if strings.Contains(log, "s3cr3t") {
+		t.Errorf("got log %q, want password redacted", log)
+	}
+}
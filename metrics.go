@@ -0,0 +1,74 @@
+package rcon
+
+import (
+	"context"
+	"errors"
+	"net"
+	"os"
+	"time"
+)
+
+// Metrics receives measurements from Conn and Client. The address is the
+// server address passed to Dial or the remote address of the conn passed to
+// Open. Methods are called synchronously, including from the Conn reader
+// goroutine, so they must not block.
+type Metrics interface {
+	// ObserveDial is called after connecting to the server.
+	ObserveDial(address string, duration time.Duration, err error)
+
+	// ObserveAuth is called after authentication.
+	ObserveAuth(address string, duration time.Duration, err error)
+
+	// ObserveExecute is called after the command response is received.
+	ObserveExecute(address string, duration time.Duration, err error)
+
+	// ObserveWrite is called after each packet write with the number of
+	// bytes written.
+	ObserveWrite(address string, n int, err error)
+
+	// ObserveRead is called after each packet read with the number of bytes
+	// read.
+	ObserveRead(address string, n int, err error)
+
+	// ObserveReconnect is called by Client each time it dials the server
+	// again.
+	ObserveReconnect(address string)
+}
+
+// ErrorType classifies err for metrics: "auth_failed", "invalid_packet_id",
+// "timeout", "canceled", "closed", "command" for invalid commands,
+// "rate_limited", "circuit_open" and "other". Empty string is returned for nil error.
+func ErrorType(err error) string {
+	switch {
+	case err == nil:
+		return ""
+	case errors.Is(err, ErrAuthFailed):
+		return "auth_failed"
+	case errors.Is(err, ErrInvalidPacketID):
+		return "invalid_packet_id"
+	case errors.Is(err, os.ErrDeadlineExceeded) || errors.Is(err, context.DeadlineExceeded):
+		return "timeout"
+	case errors.Is(err, context.Canceled):
+		return "canceled"
+	case errors.Is(err, net.ErrClosed):
+		return "closed"
+	case errors.Is(err, ErrCommandEmpty) || errors.Is(err, ErrCommandTooLong):
+		return "command"
+	case errors.Is(err, ErrRateLimited):
+		return "rate_limited"
+	case errors.Is(err, ErrCircuitOpen):
+		return "circuit_open"
+	default:
+		return "other"
+	}
+}
+
+// noMetrics is Metrics which discards measurements.
+type noMetrics struct{}
+
+func (noMetrics) ObserveDial(string, time.Duration, error)    {}
+func (noMetrics) ObserveAuth(string, time.Duration, error)    {}
+func (noMetrics) ObserveExecute(string, time.Duration, error) {}
+func (noMetrics) ObserveWrite(string, int, error)             {}
+func (noMetrics) ObserveRead(string, int, error)              {}
+func (noMetrics) ObserveReconnect(string)                     {}
@@ -0,0 +1,111 @@
+package rcon_test
+
+import (
+	"context"
+	"errors"
+	"net"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gorcon/rcon"
+	"github.com/gorcon/rcon/rcontest"
+)
+
+func TestErrorType(t *testing.T) {
+	tests := []struct {
+		err  error
+		want string
+	}{
+		{nil, ""},
+		{rcon.ErrAuthFailed, "auth_failed"},
+		{rcon.ErrInvalidPacketID, "invalid_packet_id"},
+		{context.DeadlineExceeded, "timeout"},
+		{context.Canceled, "canceled"},
+		{net.ErrClosed, "closed"},
+		{rcon.ErrCommandTooLong, "command"},
+		{rcon.ErrRateLimited, "rate_limited"},
+		{&rcon.CircuitOpenError{Address: "127.0.0.1:0"}, "circuit_open"},
+		{errors.New("test"), "other"},
+	}
+
+	for _, tt := range tests {
+		if got := rcon.ErrorType(tt.err); got != tt.want {
+			t.Errorf("got error type %q for %v, want %q", got, tt.err, tt.want)
+		}
+	}
+}
+
+func TestPrometheusMetrics(t *testing.T) {
+	server := rcontest.NewServer(
+		rcontest.SetSettings(rcontest.Settings{Password: "password"}),
+		rcontest.SetCommandHandler(commandHandler),
+	)
+	defer server.Close()
+
+	metrics := rcon.NewPrometheusMetrics()
+
+	if _, err := rcon.Dial(server.Addr(), "wrong", rcon.SetMetrics(metrics)); !errors.Is(err, rcon.ErrAuthFailed) {
+		t.Fatalf("got err %q, want %q", err, rcon.ErrAuthFailed)
+	}
+
+	conn, err := rcon.Dial(server.Addr(), "password", rcon.SetMetrics(metrics))
+	if err != nil {
+		t.Fatalf("got err %q, want %v", err, nil)
+	}
+
+	if _, err := conn.Execute("help"); err != nil {
+		t.Fatalf("got err %q, want %v", err, nil)
+	}
+
+	if _, err := conn.Execute("another"); !errors.Is(err, rcon.ErrInvalidPacketID) {
+		t.Fatalf("got err %q, want %q", err, rcon.ErrInvalidPacketID)
+	}
+
+	conn.Close()
+
+	// Nothing listens on the address after the listener is closed.
+	l, _ := net.Listen("tcp", "127.0.0.1:0")
+	address := l.Addr().String()
+	l.Close()
+
+	client := rcon.NewClient(address, "password",
+		rcon.SetConnOptions(rcon.SetMetrics(metrics)),
+		rcon.SetReconnectBackoff(time.Millisecond, time.Millisecond),
+		rcon.SetMaxReconnectAttempts(3),
+	)
+	defer client.Close()
+
+	if _, err := client.Execute("help"); err == nil {
+		t.Fatal("got no error, want dial error")
+	}
+
+	recorder := httptest.NewRecorder()
+	metrics.ServeHTTP(recorder, httptest.NewRequest("GET", "/metrics", nil))
+
+	body := recorder.Body.String()
+	label := `server="` + server.Addr() + `"`
+
+	for _, want := range []string{
+		"# TYPE rcon_command_duration_seconds histogram",
+		"rcon_dial_duration_seconds_count{" + label + "} 2",
+		"rcon_auth_duration_seconds_count{" + label + "} 2",
+		"rcon_command_duration_seconds_bucket{" + label + `,le="+Inf"} 2`,
+		"rcon_command_duration_seconds_count{" + label + "} 2",
+		"rcon_errors_total{" + label + `,operation="auth",type="auth_failed"} 1`,
+		"rcon_errors_total{" + label + `,operation="execute",type="invalid_packet_id"} 1`,
+		`rcon_errors_total{server="` + address + `",operation="dial",type="other"} 3`,
+		"rcon_written_bytes_total{" + label + "}",
+		"rcon_read_bytes_total{" + label + "}",
+		`rcon_reconnects_total{server="` + address + `"} 2`,
+	} {
+		if !strings.Contains(body, want) {
+			t.Errorf("got metrics %q, want to contain %q", body, want)
+		}
+	}
+
+	if contentType := recorder.Header().Get("Content-Type"); !strings.HasPrefix(contentType, "text/plain") {
+		t.Errorf("got content type %q, want text/plain", contentType)
+	}
+}
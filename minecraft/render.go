@@ -0,0 +1,169 @@
+package minecraft
+
+import (
+	"html"
+	"strconv"
+	"strings"
+)
+
+// ansiColors are the SGR codes of the named colors.
+var ansiColors = map[Color]string{
+	Black:       "30",
+	DarkBlue:    "34",
+	DarkGreen:   "32",
+	DarkAqua:    "36",
+	DarkRed:     "31",
+	DarkPurple:  "35",
+	Gold:        "33",
+	Gray:        "37",
+	DarkGray:    "90",
+	Blue:        "94",
+	Green:       "92",
+	Aqua:        "96",
+	Red:         "91",
+	LightPurple: "95",
+	Yellow:      "93",
+	White:       "97",
+}
+
+// ansiReset resets all SGR attributes.
+const ansiReset = "\x1b[0m"
+
+// Plain renders the span as text without formatting.
+func (s *Span) Plain() string {
+	var b strings.Builder
+
+	s.Walk(func(text string, _ Style) {
+		b.WriteString(text)
+	})
+
+	return b.String()
+}
+
+// String implements fmt.Stringer, it is the same as Plain.
+func (s *Span) String() string {
+	return s.Plain()
+}
+
+// ANSI renders the span with ANSI terminal escape sequences. Named colors
+// use the 16 colors palette, hex colors use 24-bit colors. Obfuscated text
+// is rendered as is.
+func (s *Span) ANSI() string {
+	var (
+		b       strings.Builder
+		current Style
+	)
+
+	s.Walk(func(text string, style Style) {
+		if style != current {
+			b.WriteString(ansiReset)
+
+			if sgr := ansiSGR(style); sgr != "" {
+				b.WriteString("\x1b[" + sgr + "m")
+			}
+
+			current = style
+		}
+
+		b.WriteString(text)
+	})
+
+	if current != (Style{}) {
+		b.WriteString(ansiReset)
+	}
+
+	return b.String()
+}
+
+// ansiSGR returns SGR parameters of the style.
+func ansiSGR(style Style) string {
+	var params []string
+
+	if code, ok := ansiColors[style.Color]; ok {
+		params = append(params, code)
+	} else if r, g, b, ok := style.Color.RGB(); ok {
+		params = append(params, "38;2;"+strconv.Itoa(int(r))+";"+strconv.Itoa(int(g))+";"+strconv.Itoa(int(b)))
+	}
+
+	for _, flag := range []struct {
+		set  bool
+		code string
+	}{
+		{style.Bold, "1"},
+		{style.Italic, "3"},
+		{style.Underlined, "4"},
+		{style.Strikethrough, "9"},
+	} {
+		if flag.set {
+			params = append(params, flag.code)
+		}
+	}
+
+	return strings.Join(params, ";")
+}
+
+// HTML renders the span as HTML. Styled text is wrapped in span elements
+// with inline CSS, obfuscated text gets "obfuscated" class to be animated by
+// the page. Text is escaped, so the result is safe to embed.
+func (s *Span) HTML() string {
+	var b strings.Builder
+
+	s.Walk(func(text string, style Style) {
+		css, class := htmlStyle(style)
+		if css == "" && class == "" {
+			b.WriteString(html.EscapeString(text))
+
+			return
+		}
+
+		b.WriteString("<span")
+
+		if class != "" {
+			b.WriteString(` class="` + class + `"`)
+		}
+
+		if css != "" {
+			b.WriteString(` style="` + css + `"`)
+		}
+
+		b.WriteString(">" + html.EscapeString(text) + "</span>")
+	})
+
+	return b.String()
+}
+
+// htmlStyle returns inline CSS and class of the style.
+func htmlStyle(style Style) (string, string) {
+	var declarations, decorations []string
+
+	if hex := style.Color.Hex(); hex != "" {
+		declarations = append(declarations, "color:"+hex)
+	}
+
+	if style.Bold {
+		declarations = append(declarations, "font-weight:bold")
+	}
+
+	if style.Italic {
+		declarations = append(declarations, "font-style:italic")
+	}
+
+	if style.Underlined {
+		decorations = append(decorations, "underline")
+	}
+
+	if style.Strikethrough {
+		decorations = append(decorations, "line-through")
+	}
+
+	if len(decorations) > 0 {
+		declarations = append(declarations, "text-decoration:"+strings.Join(decorations, " "))
+	}
+
+	var class string
+	if style.Obfuscated {
+		class = "obfuscated"
+	}
+
+	return strings.Join(declarations, ";"), class
+}
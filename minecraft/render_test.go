@@ -0,0 +1,54 @@
+package minecraft_test
+
+import (
+	"testing"
+
+	"github.com/gorcon/rcon/minecraft"
+)
+
+func TestSpan_Plain(t *testing.T) {
+	span := minecraft.ParseLegacy("§6[Server] §fHello")
+
+	if got := span.Plain(); got != "[Server] Hello" {
+		t.Errorf("got %q, want %q", got, "[Server] Hello")
+	}
+
+	if got := span.String(); got != "[Server] Hello" {
+		t.Errorf("got %q, want %q", got, "[Server] Hello")
+	}
+}
+
+func TestSpan_ANSI(t *testing.T) {
+	tests := []struct {
+		text string
+		want string
+	}{
+		{"plain", "plain"},
+		{"§c§lHi§r!", "\x1b[0m\x1b[91;1mHi\x1b[0m!"},
+		{"a§x§1§2§3§4§5§6b§nc", "a\x1b[0m\x1b[38;2;18;52;86mb\x1b[0m\x1b[38;2;18;52;86;4mc\x1b[0m"},
+	}
+
+	for _, tt := range tests {
+		if got := minecraft.ParseLegacy(tt.text).ANSI(); got != tt.want {
+			t.Errorf("got %q, want %q", got, tt.want)
+		}
+	}
+}
+
+func TestSpan_HTML(t *testing.T) {
+	tests := []struct {
+		text string
+		want string
+	}{
+		{"<b>&", "&lt;b&gt;&amp;"},
+		{"§4§lA§r§n§mB", `<span style="color:#AA0000;font-weight:bold">A</span>` +
+			`<span style="text-decoration:underline line-through">B</span>`},
+		{"§o§kC", `<span class="obfuscated" style="font-style:italic">C</span>`},
+	}
+
+	for _, tt := range tests {
+		if got := minecraft.ParseLegacy(tt.text).HTML(); got != tt.want {
+			t.Errorf("got %q, want %q", got, tt.want)
+		}
+	}
+}
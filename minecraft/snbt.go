@@ -0,0 +1,417 @@
+package minecraft
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// ErrInvalidSNBT is returned when the text is not valid SNBT.
+var ErrInvalidSNBT = errors.New("invalid snbt")
+
+// dataPrefixRegexp matches the message before the data of data get command.
+var dataPrefixRegexp = regexp.MustCompile(`^[^\n]*? has the following (?:entity data|block data|contents): `)
+
+// StripDataPrefix returns the response to data get command without the
+// "Steve has the following entity data: " message, which precedes the data
+// of entities, blocks and storages.
+func StripDataPrefix(s string) string {
+	s = strings.TrimSpace(s)
+
+	if match := dataPrefixRegexp.FindStringIndex(s); match != nil {
+		return s[match[1]:]
+	}
+
+	return s
+}
+
+// ParseDataGet parses the response to data get command. It is a shortcut for
+// ParseSNBT of StripDataPrefix.
+func ParseDataGet(s string) (any, error) {
+	return ParseSNBT(StripDataPrefix(s))
+}
+
+// ParseSNBT parses stringified NBT into Go values:
+//
+//   - compound {a: 1} is map[string]any;
+//   - list [1, 2] is []any;
+//   - arrays [B; 1b], [I; 1] and [L; 1L] are []int8, []int32 and []int64;
+//   - numbers with b, s, l, f and d suffixes are int8, int16, int64, float32
+//     and float64, without a suffix they are int32 or float64 if they have a
+//     fraction or exponent;
+//   - true and false are int8 1 and 0 like Minecraft stores them;
+//   - quoted and other unquoted values are strings.
+func ParseSNBT(s string) (any, error) {
+	p := &snbtParser{s: s}
+
+	value, err := p.value()
+	if err != nil {
+		return nil, err
+	}
+
+	if p.skipSpaces(); p.pos < len(p.s) {
+		return nil, p.errorf("unexpected %q after value", p.s[p.pos])
+	}
+
+	return value, nil
+}
+
+// snbtParser is the recursive descent SNBT parser.
+type snbtParser struct {
+	s   string
+	pos int
+}
+
+// errorf returns ErrInvalidSNBT with the position.
+func (p *snbtParser) errorf(format string, args ...any) error {
+	return fmt.Errorf("minecraft: %w at offset %d: %s", ErrInvalidSNBT, p.pos, fmt.Sprintf(format, args...))
+}
+
+// skipSpaces skips whitespaces.
+func (p *snbtParser) skipSpaces() {
+	for p.pos < len(p.s) && strings.IndexByte(" \t\r\n", p.s[p.pos]) >= 0 {
+		p.pos++
+	}
+}
+
+// expect consumes the character or returns an error.
+func (p *snbtParser) expect(c byte) error {
+	if p.skipSpaces(); p.pos >= len(p.s) || p.s[p.pos] != c {
+		return p.errorf("expected %q", c)
+	}
+
+	p.pos++
+
+	return nil
+}
+
+// next returns the next non-space character or 0 at the end.
+func (p *snbtParser) next() byte {
+	if p.skipSpaces(); p.pos < len(p.s) {
+		return p.s[p.pos]
+	}
+
+	return 0
+}
+
+// value parses any value.
+func (p *snbtParser) value() (any, error) {
+	switch p.next() {
+	case '{':
+		return p.compound()
+	case '[':
+		return p.list()
+	case '"', '\'':
+		return p.quoted()
+	case 0:
+		return nil, p.errorf("unexpected end of input")
+	default:
+		token := p.unquoted()
+		if token == "" {
+			return nil, p.errorf("unexpected %q", p.s[p.pos])
+		}
+
+		return parseScalar(token), nil
+	}
+}
+
+// compound parses {key: value, ...}.
+func (p *snbtParser) compound() (map[string]any, error) {
+	p.pos++
+
+	compound := make(map[string]any)
+
+	if p.next() == '}' {
+		p.pos++
+
+		return compound, nil
+	}
+
+	for {
+		key, err := p.key()
+		if err != nil {
+			return nil, err
+		}
+
+		if err := p.expect(':'); err != nil {
+			return nil, err
+		}
+
+		if compound[key], err = p.value(); err != nil {
+			return nil, err
+		}
+
+		switch p.next() {
+		case ',':
+			p.pos++
+		case '}':
+			p.pos++
+
+			return compound, nil
+		default:
+			return nil, p.errorf("expected ',' or '}'")
+		}
+	}
+}
+
+// key parses the compound key, which is a quoted or unquoted string.
+func (p *snbtParser) key() (string, error) {
+	if c := p.next(); c == '"' || c == '\'' {
+		return p.quoted()
+	}
+
+	if key := p.unquoted(); key != "" {
+		return key, nil
+	}
+
+	return "", p.errorf("expected key")
+}
+
+// list parses [value, ...] and typed arrays [B; ...], [I; ...] and [L; ...].
+func (p *snbtParser) list() (any, error) {
+	p.pos++
+
+	if kind, ok := p.arrayType(); ok {
+		return p.array(kind)
+	}
+
+	list := []any{}
+
+	if p.next() == ']' {
+		p.pos++
+
+		return list, nil
+	}
+
+	for {
+		value, err := p.value()
+		if err != nil {
+			return nil, err
+		}
+
+		list = append(list, value)
+
+		switch p.next() {
+		case ',':
+			p.pos++
+		case ']':
+			p.pos++
+
+			return list, nil
+		default:
+			return nil, p.errorf("expected ',' or ']'")
+		}
+	}
+}
+
+// arrayType consumes the typed array prefix like "B;" and returns its type.
+func (p *snbtParser) arrayType() (byte, bool) {
+	start := p.pos
+
+	kind := p.next()
+	if kind != 'B' && kind != 'I' && kind != 'L' {
+		return 0, false
+	}
+
+	p.pos++
+
+	if p.next() != ';' {
+		p.pos = start
+
+		return 0, false
+	}
+
+	p.pos++
+
+	return kind, true
+}
+
+// array parses the elements of the typed array.
+func (p *snbtParser) array(kind byte) (any, error) {
+	var elements []int64
+
+	for p.next() != ']' {
+		if len(elements) > 0 {
+			if err := p.expect(','); err != nil {
+				return nil, err
+			}
+		}
+
+		p.skipSpaces()
+		token := p.unquoted()
+
+		value, ok := toInt64(parseScalar(token))
+		if !ok {
+			return nil, p.errorf("invalid %c array element %q", kind, token)
+		}
+
+		elements = append(elements, value)
+	}
+
+	p.pos++
+
+	return convertArray(kind, elements), nil
+}
+
+// toInt64 converts the integer of any size to int64.
+func toInt64(value any) (int64, bool) {
+	switch v := value.(type) {
+	case int8:
+		return int64(v), true
+	case int16:
+		return int64(v), true
+	case int32:
+		return int64(v), true
+	case int64:
+		return v, true
+	default:
+		return 0, false
+	}
+}
+
+// convertArray converts the elements to the array type.
+func convertArray(kind byte, elements []int64) any {
+	switch kind {
+	case 'B':
+		array := make([]int8, len(elements))
+		for i, e := range elements {
+			array[i] = int8(e) //nolint:gosec // Byte arrays wrap like Minecraft does.
+		}
+
+		return array
+	case 'I':
+		array := make([]int32, len(elements))
+		for i, e := range elements {
+			array[i] = int32(e) //nolint:gosec // Int arrays wrap like Minecraft does.
+		}
+
+		return array
+	default:
+		if elements == nil {
+			elements = []int64{}
+		}
+
+		return elements
+	}
+}
+
+// quoted parses the quoted string with backslash escapes.
+func (p *snbtParser) quoted() (string, error) {
+	quote := p.s[p.pos]
+	p.pos++
+
+	var b strings.Builder
+
+	for p.pos < len(p.s) {
+		c := p.s[p.pos]
+		p.pos++
+
+		switch {
+		case c == quote:
+			return b.String(), nil
+		case c == '\\' && p.pos < len(p.s):
+			b.WriteByte(p.s[p.pos])
+			p.pos++
+		default:
+			b.WriteByte(c)
+		}
+	}
+
+	return "", p.errorf("unterminated string")
+}
+
+// unquoted consumes the unquoted token of characters allowed by SNBT.
+func (p *snbtParser) unquoted() string {
+	start := p.pos
+
+	for p.pos < len(p.s) && isUnquoted(p.s[p.pos]) {
+		p.pos++
+	}
+
+	return p.s[start:p.pos]
+}
+
+// isUnquoted reports whether c is allowed in unquoted strings.
+func isUnquoted(c byte) bool {
+	return c >= '0' && c <= '9' || c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' ||
+		c == '_' || c == '-' || c == '.' || c == '+'
+}
+
+// integerRegexp and floatRegexp match SNBT numbers without a suffix.
+var (
+	integerRegexp = regexp.MustCompile(`^[-+]?(?:0|[1-9][0-9]*)$`)
+	floatRegexp   = regexp.MustCompile(`^[-+]?(?:[0-9]+\.?|[0-9]*\.[0-9]+)(?:e[-+]?[0-9]+)?$`)
+)
+
+// parseScalar converts the unquoted token to a number, or returns it as a
+// string if it is not a number.
+func parseScalar(token string) any {
+	switch strings.ToLower(token) {
+	case "true":
+		return int8(1)
+	case "false":
+		return int8(0)
+	}
+
+	if number, ok := parseNumber(token); ok {
+		return number
+	}
+
+	return token
+}
+
+// parseNumber parses the number with an optional type suffix.
+func parseNumber(token string) (any, bool) {
+	if token == "" {
+		return nil, false
+	}
+
+	body, suffix := token[:len(token)-1], token[len(token)-1]|0x20
+
+	switch suffix {
+	case 'b':
+		return parseInt[int8](body, 8)
+	case 's':
+		return parseInt[int16](body, 16)
+	case 'l':
+		return parseInt[int64](body, 64)
+	case 'f':
+		if value, err := strconv.ParseFloat(body, 32); err == nil && floatRegexp.MatchString(strings.ToLower(body)) {
+			return float32(value), true
+		}
+
+		return nil, false
+	case 'd':
+		if value, err := strconv.ParseFloat(body, 64); err == nil && floatRegexp.MatchString(strings.ToLower(body)) {
+			return value, true
+		}
+
+		return nil, false
+	}
+
+	if integerRegexp.MatchString(token) {
+		return parseInt[int32](token, 32)
+	}
+
+	if value, err := strconv.ParseFloat(token, 64); err == nil && floatRegexp.MatchString(strings.ToLower(token)) {
+		return value, true
+	}
+
+	return nil, false
+}
+
+// parseInt parses the integer of the bit size.
+func parseInt[T int8 | int16 | int32 | int64](s string, bitSize int) (any, bool) {
+	if !integerRegexp.MatchString(s) {
+		return nil, false
+	}
+
+	value, err := strconv.ParseInt(s, 10, bitSize)
+	if err != nil {
+		return nil, false
+	}
+
+	return T(value), true
+}
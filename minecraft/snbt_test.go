@@ -0,0 +1,140 @@
+package minecraft_test
+
+import (
+	"errors"
+	"os"
+	"reflect"
+	"testing"
+
+	"github.com/gorcon/rcon/minecraft"
+)
+
+func TestParseSNBT(t *testing.T) {
+	tests := []struct {
+		name string
+		snbt string
+		want any
+	}{
+		{"byte", "1b", int8(1)},
+		{"short", "-300S", int16(-300)},
+		{"int", "2147483647", int32(2147483647)},
+		{"long", "1718445296000L", int64(1718445296000)},
+		{"float", "0.05f", float32(0.05)},
+		{"double", "12.5d", 12.5},
+		{"double without suffix", "1.5e3", 1500.0},
+		{"bool", "true", int8(1)},
+		{"int out of range", "2147483648", "2147483648"},
+		{"unquoted string", "minecraft:stone", nil},
+		{"quoted strings", `["a \"b\"", 'it\'s', "Ёжик"]`, []any{`a "b"`, "it's", "Ёжик"}},
+		{"compound", `{a: 1, "b c": {}, d: [], 'e': 1.0f}`, map[string]any{
+			"a": int32(1), "b c": map[string]any{}, "d": []any{}, "e": float32(1),
+		}},
+		{"byte array", "[B; 1b, -2b, 3B]", []int8{1, -2, 3}},
+		{"int array", "[I;1,2 , 3]", []int32{1, 2, 3}},
+		{"long array", "[L; ]", []int64{}},
+		{"nested list", "[[1s], [B; 1b], {x: 2}]", []any{
+			[]any{int16(1)}, []int8{1}, map[string]any{"x": int32(2)},
+		}},
+		{"string starting with array type", "[Bob, I]", []any{"Bob", "I"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := minecraft.ParseSNBT(tt.snbt)
+
+			if tt.want == nil {
+				// Unquoted strings are limited to [0-9A-Za-z_\-.+].
+				if !errors.Is(err, minecraft.ErrInvalidSNBT) {
+					t.Errorf("got %v, %v, want %q", got, err, minecraft.ErrInvalidSNBT)
+				}
+
+				return
+			}
+
+			if err != nil {
+				t.Fatalf("got err %q, want %v", err, nil)
+			}
+
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("got %#v, want %#v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseSNBT_Invalid(t *testing.T) {
+	for _, snbt := range []string{"", "{", "{a 1}", "[1, 2", `"open`, "[I; 1.5f]", "{a: 1} b", "[1 2]"} {
+		if _, err := minecraft.ParseSNBT(snbt); !errors.Is(err, minecraft.ErrInvalidSNBT) {
+			t.Errorf("%q: got err %v, want %q", snbt, err, minecraft.ErrInvalidSNBT)
+		}
+	}
+}
+
+func TestStripDataPrefix(t *testing.T) {
+	tests := []struct {
+		response string
+		want     string
+	}{
+		{"Steve has the following entity data: [-9.5d, 71.0d, 12.3d]", "[-9.5d, 71.0d, 12.3d]"},
+		{"-1, 64, 3 has the following block data: {Items: []}", "{Items: []}"},
+		{"Storage minecraft:test has the following contents: {a: 1}\n", "{a: 1}"},
+		{"Steve has the following entity data: 20.0f", "20.0f"},
+		{"{a: 1}", "{a: 1}"},
+	}
+
+	for _, tt := range tests {
+		if got := minecraft.StripDataPrefix(tt.response); got != tt.want {
+			t.Errorf("got %q, want %q", got, tt.want)
+		}
+	}
+}
+
+func TestParseDataGet(t *testing.T) {
+	response, err := os.ReadFile("testdata/entity.txt")
+	if err != nil {
+		t.Fatalf("got err %q, want %v", err, nil)
+	}
+
+	data, err := minecraft.ParseDataGet(string(response))
+	if err != nil {
+		t.Fatalf("got err %q, want %v", err, nil)
+	}
+
+	entity, ok := data.(map[string]any)
+	if !ok {
+		t.Fatalf("got %T, want map[string]any", data)
+	}
+
+	if got, want := entity["Pos"], []any{-9.5, 71.0, 12.300000011920929}; !reflect.DeepEqual(got, want) {
+		t.Errorf("got Pos %#v, want %#v", got, want)
+	}
+
+	if got, want := entity["UUID"], []int32{-1478383290, -1234255591, -1879655307, 1146386386}; !reflect.DeepEqual(got, want) {
+		t.Errorf("got UUID %#v, want %#v", got, want)
+	}
+
+	if got, want := entity["Health"], float32(20); got != want {
+		t.Errorf("got Health %#v, want %#v", got, want)
+	}
+
+	inventory, _ := entity["Inventory"].([]any)
+	if len(inventory) != 2 {
+		t.Fatalf("got inventory %#v, want 2 items", entity["Inventory"])
+	}
+
+	sword, _ := inventory[0].(map[string]any)
+	if got, want := sword["id"], "minecraft:diamond_sword"; got != want {
+		t.Errorf("got id %#v, want %#v", got, want)
+	}
+
+	tag, _ := sword["tag"].(map[string]any)
+	display, _ := tag["display"].(map[string]any)
+
+	if got, want := display["Name"], `{"text":"Steve's sword"}`; got != want {
+		t.Errorf("got display name %#v, want %#v", got, want)
+	}
+
+	if got, want := entity["LastSeen"], int64(1718445296000); got != want {
+		t.Errorf("got LastSeen %#v, want %#v", got, want)
+	}
+}
@@ -0,0 +1,541 @@
+// Package minecraft contains helpers for Minecraft RCON responses.
+package minecraft
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Color is the text color. It is one of the named colors or a hex color in
+// #RRGGBB format. Empty Color means the default color.
+type Color string
+
+// Named colors of formatting codes 0-9 and a-f.
+const (
+	Black       Color = "black"
+	DarkBlue    Color = "dark_blue"
+	DarkGreen   Color = "dark_green"
+	DarkAqua    Color = "dark_aqua"
+	DarkRed     Color = "dark_red"
+	DarkPurple  Color = "dark_purple"
+	Gold        Color = "gold"
+	Gray        Color = "gray"
+	DarkGray    Color = "dark_gray"
+	Blue        Color = "blue"
+	Green       Color = "green"
+	Aqua        Color = "aqua"
+	Red         Color = "red"
+	LightPurple Color = "light_purple"
+	Yellow      Color = "yellow"
+	White       Color = "white"
+)
+
+// namedColors are the hex values of the named colors.
+var namedColors = map[Color]string{
+	Black:       "#000000",
+	DarkBlue:    "#0000AA",
+	DarkGreen:   "#00AA00",
+	DarkAqua:    "#00AAAA",
+	DarkRed:     "#AA0000",
+	DarkPurple:  "#AA00AA",
+	Gold:        "#FFAA00",
+	Gray:        "#AAAAAA",
+	DarkGray:    "#555555",
+	Blue:        "#5555FF",
+	Green:       "#55FF55",
+	Aqua:        "#55FFFF",
+	Red:         "#FF5555",
+	LightPurple: "#FF55FF",
+	Yellow:      "#FFFF55",
+	White:       "#FFFFFF",
+}
+
+// colorCodes are the colors of the formatting codes.
+var colorCodes = map[byte]Color{
+	'0': Black, '1': DarkBlue, '2': DarkGreen, '3': DarkAqua,
+	'4': DarkRed, '5': DarkPurple, '6': Gold, '7': Gray,
+	'8': DarkGray, '9': Blue, 'a': Green, 'b': Aqua,
+	'c': Red, 'd': LightPurple, 'e': Yellow, 'f': White,
+}
+
+// Hex returns the color in #RRGGBB format or empty string for the default
+// or unknown color.
+func (c Color) Hex() string {
+	if hex, ok := namedColors[c]; ok {
+		return hex
+	}
+
+	if isHexColor(string(c)) {
+		return strings.ToUpper(string(c))
+	}
+
+	return ""
+}
+
+// RGB returns the red, green and blue components of the color. The ok is
+// false for the default or unknown color.
+func (c Color) RGB() (r, g, b uint8, ok bool) {
+	hex := c.Hex()
+	if hex == "" {
+		return 0, 0, 0, false
+	}
+
+	value, _ := strconv.ParseUint(hex[1:], 16, 32)
+
+	return uint8(value >> 16), uint8(value >> 8), uint8(value), true //nolint:gosec // Masked by conversion
+}
+
+// isHexColor reports whether s is a color in #RRGGBB format.
+func isHexColor(s string) bool {
+	if len(s) != 7 || s[0] != '#' {
+		return false
+	}
+
+	_, err := strconv.ParseUint(s[1:], 16, 32)
+
+	return err == nil
+}
+
+// Style is the text formatting.
+type Style struct {
+	Color         Color
+	Bold          bool
+	Italic        bool
+	Underlined    bool
+	Strikethrough bool
+	Obfuscated    bool
+}
+
+// Span is a node of the text tree. Its Text is followed by the text of its
+// Children. Style is the effective style of the span, inherited styles are
+// already applied, so spans can be rendered independently.
+type Span struct {
+	Text     string
+	Style    Style
+	Children []*Span
+}
+
+// Walk calls fn for the span text and then for its children depth-first.
+// Empty texts are skipped.
+func (s *Span) Walk(fn func(text string, style Style)) {
+	if s.Text != "" {
+		fn(s.Text, s.Style)
+	}
+
+	for _, child := range s.Children {
+		child.Walk(fn)
+	}
+}
+
+// Parse parses JSON text component if s looks like one and falls back to
+// text with formatting codes otherwise.
+func Parse(s string) *Span {
+	if trimmed := strings.TrimSpace(s); trimmed != "" && strings.ContainsRune("{[\"", rune(trimmed[0])) {
+		if span, err := ParseJSON([]byte(trimmed)); err == nil {
+			return span
+		}
+	}
+
+	return ParseLegacy(s)
+}
+
+// Strip returns s without formatting codes.
+func Strip(s string) string {
+	return ParseLegacy(s).Plain()
+}
+
+// ParseLegacy parses text with § formatting codes. Color codes reset the
+// formatting like Minecraft does, §r resets to the default style. Hex colors
+// in §x§R§R§G§G§B§B format used by Bukkit servers are supported too. Unknown
+// codes are dropped.
+func ParseLegacy(s string) *Span {
+	return parseLegacy(s, Style{})
+}
+
+// parseLegacy parses text with formatting codes, base is the style reset by
+// §r.
+func parseLegacy(s string, base Style) *Span {
+	root := &Span{Style: base}
+
+	if !strings.Contains(s, "§") {
+		root.Text = s
+
+		return root
+	}
+
+	style := base
+	text := strings.Builder{}
+
+	flush := func() {
+		if text.Len() > 0 {
+			root.Children = append(root.Children, &Span{Text: text.String(), Style: style})
+			text.Reset()
+		}
+	}
+
+	for {
+		before, after, found := strings.Cut(s, "§")
+		text.WriteString(before)
+
+		if !found {
+			break
+		}
+
+		flush()
+
+		s = after
+		if s == "" {
+			break
+		}
+
+		var n int
+
+		style, n = applyCode(style, base, s)
+		s = s[n:]
+	}
+
+	flush()
+
+	return root
+}
+
+// applyCode returns the style after the formatting code at the start of s
+// and the length of the code without the leading §.
+func applyCode(style Style, base Style, s string) (Style, int) {
+	code := s[0]
+	if code >= 'A' && code <= 'Z' {
+		code += 'a' - 'A'
+	}
+
+	if color, ok := colorCodes[code]; ok {
+		return Style{Color: color}, 1
+	}
+
+	switch code {
+	case 'k':
+		style.Obfuscated = true
+	case 'l':
+		style.Bold = true
+	case 'm':
+		style.Strikethrough = true
+	case 'n':
+		style.Underlined = true
+	case 'o':
+		style.Italic = true
+	case 'r':
+		style = base
+	case 'x':
+		if hex, n := hexCode(s[1:]); n > 0 {
+			return Style{Color: Color(hex)}, n + 1
+		}
+	}
+
+	return style, 1
+}
+
+// hexCode parses §R§R§G§G§B§B sequence after §x and returns the color in
+// #RRGGBB format and the length of the sequence.
+func hexCode(s string) (string, int) {
+	const digits, marker = 6, "§"
+
+	hex := []byte{'#'}
+
+	for i := 0; i < digits; i++ {
+		offset := i * (len(marker) + 1)
+		if len(s) < offset+len(marker)+1 || s[offset:offset+len(marker)] != marker {
+			return "", 0
+		}
+
+		hex = append(hex, s[offset+len(marker)])
+	}
+
+	if !isHexColor(string(hex)) {
+		return "", 0
+	}
+
+	return strings.ToUpper(string(hex)), digits * (len(marker) + 1)
+}
+
+// component is the JSON text component.
+type component struct {
+	Text      *json.RawMessage  `json:"text"`
+	Translate string            `json:"translate"`
+	Fallback  *string           `json:"fallback"`
+	With      []json.RawMessage `json:"with"`
+	Keybind   string            `json:"keybind"`
+	Selector  string            `json:"selector"`
+	Score     *struct {
+		Name      string `json:"name"`
+		Objective string `json:"objective"`
+		Value     string `json:"value"`
+	} `json:"score"`
+
+	Color         string `json:"color"`
+	Bold          *bool  `json:"bold"`
+	Italic        *bool  `json:"italic"`
+	Underlined    *bool  `json:"underlined"`
+	Strikethrough *bool  `json:"strikethrough"`
+	Obfuscated    *bool  `json:"obfuscated"`
+
+	Extra []json.RawMessage `json:"extra"`
+}
+
+// ParseJSON parses JSON text component used by tellraw, title and other
+// commands. The component may be a string, an array or an object with text,
+// translate, keybind, score or selector content. Translation keys are not
+// resolved, the fallback or the key itself is rendered with its %s
+// arguments substituted.
+func ParseJSON(data []byte) (*Span, error) {
+	span, err := parseComponent(data, Style{})
+	if err != nil {
+		return nil, fmt.Errorf("minecraft: parse text component: %w", err)
+	}
+
+	return span, nil
+}
+
+// parseComponent parses the component which inherits the parent style.
+func parseComponent(data json.RawMessage, parent Style) (*Span, error) {
+	data = bytes.TrimSpace(data)
+	if len(data) == 0 {
+		return nil, fmt.Errorf("unexpected end of JSON input")
+	}
+
+	switch data[0] {
+	case '"':
+		var text string
+		if err := json.Unmarshal(data, &text); err != nil {
+			return nil, err
+		}
+
+		return parseLegacy(text, parent), nil
+	case '[':
+		var list []json.RawMessage
+		if err := json.Unmarshal(data, &list); err != nil {
+			return nil, err
+		}
+
+		return parseList(list, parent)
+	case '{':
+		var c component
+		if err := json.Unmarshal(data, &c); err != nil {
+			return nil, err
+		}
+
+		return c.parse(parent)
+	default:
+		// Numbers and booleans are rendered as is.
+		var value any
+		if err := json.Unmarshal(data, &value); err != nil {
+			return nil, err
+		}
+
+		return &Span{Text: string(data), Style: parent}, nil
+	}
+}
+
+// parseList parses the array component. The elements after the first one
+// inherit its style like its extra.
+func parseList(list []json.RawMessage, parent Style) (*Span, error) {
+	if len(list) == 0 {
+		return &Span{Style: parent}, nil
+	}
+
+	first, err := parseComponent(list[0], parent)
+	if err != nil {
+		return nil, err
+	}
+
+	children, err := parseComponents(list[1:], first.Style)
+	if err != nil {
+		return nil, err
+	}
+
+	first.Children = append(first.Children, children...)
+
+	return first, nil
+}
+
+// parseComponents parses the components which inherit the parent style.
+func parseComponents(list []json.RawMessage, parent Style) ([]*Span, error) {
+	spans := make([]*Span, 0, len(list))
+
+	for _, data := range list {
+		span, err := parseComponent(data, parent)
+		if err != nil {
+			return nil, err
+		}
+
+		spans = append(spans, span)
+	}
+
+	return spans, nil
+}
+
+// parse converts the object component to the span.
+func (c *component) parse(parent Style) (*Span, error) {
+	style := c.style(parent)
+
+	var span *Span
+
+	switch {
+	case c.Text != nil:
+		text, err := primitive(*c.Text)
+		if err != nil {
+			return nil, err
+		}
+
+		span = parseLegacy(text, style)
+	case c.Translate != "":
+		args, err := parseComponents(c.With, style)
+		if err != nil {
+			return nil, err
+		}
+
+		format := c.Translate
+		if c.Fallback != nil {
+			format = *c.Fallback
+		}
+
+		span = &Span{Style: style, Children: translate(format, args, style)}
+	case c.Keybind != "":
+		span = &Span{Text: c.Keybind, Style: style}
+	case c.Score != nil:
+		span = &Span{Text: c.Score.Value, Style: style}
+	default:
+		span = &Span{Text: c.Selector, Style: style}
+	}
+
+	extra, err := parseComponents(c.Extra, style)
+	if err != nil {
+		return nil, err
+	}
+
+	span.Children = append(span.Children, extra...)
+
+	return span, nil
+}
+
+// style returns the component style inheriting unset fields from parent.
+func (c *component) style(parent Style) Style {
+	style := parent
+
+	if _, ok := namedColors[Color(c.Color)]; ok || isHexColor(c.Color) {
+		style.Color = Color(c.Color)
+	} else if c.Color == "reset" {
+		style.Color = ""
+	}
+
+	for _, flag := range []struct {
+		value *bool
+		field *bool
+	}{
+		{c.Bold, &style.Bold},
+		{c.Italic, &style.Italic},
+		{c.Underlined, &style.Underlined},
+		{c.Strikethrough, &style.Strikethrough},
+		{c.Obfuscated, &style.Obfuscated},
+	} {
+		if flag.value != nil {
+			*flag.field = *flag.value
+		}
+	}
+
+	return style
+}
+
+// primitive returns the string, number or boolean JSON value as text.
+func primitive(data json.RawMessage) (string, error) {
+	var text string
+	if err := json.Unmarshal(data, &text); err == nil {
+		return text, nil
+	}
+
+	var value any
+	if err := json.Unmarshal(data, &value); err != nil {
+		return "", err
+	}
+
+	return string(bytes.TrimSpace(data)), nil
+}
+
+// translate substitutes %s and %N$s placeholders in format with args, %%
+// is replaced with %.
+func translate(format string, args []*Span, style Style) []*Span {
+	var (
+		spans []*Span
+		text  strings.Builder
+		next  int
+	)
+
+	for i := 0; i < len(format); i++ {
+		if format[i] != '%' || i+1 == len(format) {
+			text.WriteByte(format[i])
+
+			continue
+		}
+
+		index, n := placeholder(format[i+1:], next)
+		if n == 0 {
+			text.WriteByte(format[i])
+
+			continue
+		}
+
+		i += n
+
+		if index < 0 {
+			text.WriteByte('%')
+
+			continue
+		}
+
+		if text.Len() > 0 {
+			spans = append(spans, &Span{Text: text.String(), Style: style})
+			text.Reset()
+		}
+
+		if index < len(args) {
+			spans = append(spans, args[index])
+		}
+
+		next = index + 1
+	}
+
+	if text.Len() > 0 {
+		spans = append(spans, &Span{Text: text.String(), Style: style})
+	}
+
+	return spans
+}
+
+// placeholder parses the placeholder after % and returns the argument index
+// and the placeholder length. The index is -1 for %% and the length is 0
+// if s does not start with a placeholder.
+func placeholder(s string, next int) (int, int) {
+	switch {
+	case s[0] == '%':
+		return -1, 1
+	case s[0] == 's':
+		return next, 1
+	}
+
+	digits := 0
+	for digits < len(s) && s[digits] >= '0' && s[digits] <= '9' {
+		digits++
+	}
+
+	if digits == 0 || !strings.HasPrefix(s[digits:], "$s") {
+		return 0, 0
+	}
+
+	index, err := strconv.Atoi(s[:digits])
+	if err != nil || index == 0 {
+		return 0, 0
+	}
+
+	return index - 1, digits + 2
+}
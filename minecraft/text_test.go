@@ -0,0 +1,152 @@
+package minecraft_test
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/gorcon/rcon/minecraft"
+)
+
+// segment is the rendered text with its style.
+type segment struct {
+	Text  string
+	Style minecraft.Style
+}
+
+// segments returns the flattened span tree.
+func segments(span *minecraft.Span) []segment {
+	var result []segment
+
+	span.Walk(func(text string, style minecraft.Style) {
+		result = append(result, segment{Text: text, Style: style})
+	})
+
+	return result
+}
+
+func TestParseLegacy(t *testing.T) {
+	tests := []struct {
+		name string
+		text string
+		want []segment
+	}{
+		{"plain", "There are 0 of a max of 20 players online: ", []segment{
+			{Text: "There are 0 of a max of 20 players online: "},
+		}},
+		{"colors and formatting", "§6Gold §lbold§r plain", []segment{
+			{Text: "Gold ", Style: minecraft.Style{Color: minecraft.Gold}},
+			{Text: "bold", Style: minecraft.Style{Color: minecraft.Gold, Bold: true}},
+			{Text: " plain"},
+		}},
+		{"color resets formatting", "§l§oA§cB", []segment{
+			{Text: "A", Style: minecraft.Style{Bold: true, Italic: true}},
+			{Text: "B", Style: minecraft.Style{Color: minecraft.Red}},
+		}},
+		{"upper case codes", "§AGreen§NU", []segment{
+			{Text: "Green", Style: minecraft.Style{Color: minecraft.Green}},
+			{Text: "U", Style: minecraft.Style{Color: minecraft.Green, Underlined: true}},
+		}},
+		{"hex color", "§x§f§f§8§8§0§0Orange§kX", []segment{
+			{Text: "Orange", Style: minecraft.Style{Color: "#FF8800"}},
+			{Text: "X", Style: minecraft.Style{Color: "#FF8800", Obfuscated: true}},
+		}},
+		{"unknown and trailing codes", "a§zb§", []segment{
+			{Text: "a"},
+			{Text: "b"},
+		}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := segments(minecraft.ParseLegacy(tt.text)); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("got %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestStrip(t *testing.T) {
+	if got := minecraft.Strip("§eSteve§r: §7hello"); got != "Steve: hello" {
+		t.Errorf("got %q, want %q", got, "Steve: hello")
+	}
+}
+
+func TestParseJSON(t *testing.T) {
+	tests := []struct {
+		name string
+		data string
+		want []segment
+	}{
+		{"string", `"§cred"`, []segment{
+			{Text: "red", Style: minecraft.Style{Color: minecraft.Red}},
+		}},
+		{"object with extra", `{"text":"Hi ","color":"gold","bold":true,"extra":[{"text":"all","bold":false},"!"]}`,
+			[]segment{
+				{Text: "Hi ", Style: minecraft.Style{Color: minecraft.Gold, Bold: true}},
+				{Text: "all", Style: minecraft.Style{Color: minecraft.Gold}},
+				{Text: "!", Style: minecraft.Style{Color: minecraft.Gold, Bold: true}},
+			}},
+		{"array inherits first", `[{"text":"a","italic":true},"b",{"text":"c","color":"#00ff00"}]`, []segment{
+			{Text: "a", Style: minecraft.Style{Italic: true}},
+			{Text: "b", Style: minecraft.Style{Italic: true}},
+			{Text: "c", Style: minecraft.Style{Color: "#00ff00", Italic: true}},
+		}},
+		{"translate", `{"translate":"%2$s gave %1$s %s %%","with":["Alex",{"text":"Steve","color":"aqua"}]}`,
+			[]segment{
+				{Text: "Steve", Style: minecraft.Style{Color: minecraft.Aqua}},
+				{Text: " gave "},
+				{Text: "Alex"},
+				{Text: " "},
+				{Text: "Steve", Style: minecraft.Style{Color: minecraft.Aqua}},
+				{Text: " %"},
+			}},
+		{"translate fallback", `{"translate":"unknown.key","fallback":"Hello %s","with":["Alex"]}`, []segment{
+			{Text: "Hello "},
+			{Text: "Alex"},
+		}},
+		{"score and keybind", `[{"score":{"name":"Steve","objective":"kills","value":"12"}},{"keybind":"key.jump"}]`,
+			[]segment{
+				{Text: "12"},
+				{Text: "key.jump"},
+			}},
+		{"number text", `{"text":42}`, []segment{
+			{Text: "42"},
+		}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			span, err := minecraft.ParseJSON([]byte(tt.data))
+			if err != nil {
+				t.Fatalf("got err %q, want %v", err, nil)
+			}
+
+			if got := segments(span); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("got %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+
+	t.Run("invalid", func(t *testing.T) {
+		if _, err := minecraft.ParseJSON([]byte(`{"text":`)); err == nil {
+			t.Errorf("got err %v, want error", err)
+		}
+	})
+}
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		text string
+		want string
+	}{
+		{`{"text":"json"}`, "json"},
+		{"§aplain", "plain"},
+		{"[not json", "[not json"},
+	}
+
+	for _, tt := range tests {
+		if got := minecraft.Parse(tt.text).Plain(); got != tt.want {
+			t.Errorf("got %q, want %q", got, tt.want)
+		}
+	}
+}
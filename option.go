@@ -1,12 +1,33 @@
 package rcon
 
-import "time"
+import (
+	"crypto/tls"
+	"log/slog"
+	"time"
+)
 
 // Settings contains option to Conn.
 type Settings struct {
 	dialTimeout   time.Duration
 	deadline      time.Duration
 	maxCommandLen int
+	multiPacket   MultiPacketStrategy
+	staleHandler  func(packet *Packet)
+	maxInFlight   int
+	dialect       Dialect
+	tlsConfig     *tls.Config
+	dialer        Dialer
+	logger        *slog.Logger
+	metrics       Metrics
+	tracer        Tracer
+	limiter       *RateLimiter
+	retryPolicy   RetryPolicy
+	breaker       *CircuitBreaker
+	encoding      Encoding
+
+	heartbeatInterval time.Duration
+	heartbeatCommand  string
+	heartbeatHandler  func(latency time.Duration, err error)
 }
 
 // DefaultSettings provides default deadline settings to Conn.
@@ -14,18 +35,32 @@ var DefaultSettings = Settings{
 	dialTimeout:   DefaultDialTimeout,
 	deadline:      DefaultDeadline,
 	maxCommandLen: DefaultMaxCommandLen,
+	multiPacket:   MultiPacketNone,
+	dialect:       DialectGeneric,
+	metrics:       noMetrics{},
+	tracer:        noTracer{},
+	encoding:      EncodingUTF8,
 }
 
 // Option allows to inject settings to Settings.
 type Option func(s *Settings)
 
-// SetDialTimeout injects dial Timeout to Settings.
+// SetDialTimeout injects dial Timeout to Settings. The timeout covers both
+// TCP dial and TLS handshake.
 func SetDialTimeout(timeout time.Duration) Option {
 	return func(s *Settings) {
 		s.dialTimeout = timeout
 	}
 }
 
+// SetDialer injects dialer used by Dial and DialContext to connect to the
+// server to Settings. The dial timeout is applied to the dialer context.
+func SetDialer(dialer Dialer) Option {
+	return func(s *Settings) {
+		s.dialer = dialer
+	}
+}
+
 // SetDeadline injects read/write Timeout to Settings.
 func SetDeadline(timeout time.Duration) Option {
 	return func(s *Settings) {
@@ -39,3 +74,152 @@ func SetMaxCommandLen(maxCommandLen int) Option {
 		s.maxCommandLen = maxCommandLen
 	}
 }
+
+// SetMultiPacket injects multi-packet response strategy to Settings.
+func SetMultiPacket(strategy MultiPacketStrategy) Option {
+	return func(s *Settings) {
+		s.multiPacket = strategy
+	}
+}
+
+// SetStaleHandler injects handler which is called for each stale packet, that
+// is a response to a previous request which has been abandoned, for example
+// after a timeout. Stale packets are discarded if no handler is set.
+// The handler is called from the Conn reader goroutine, so it must not block.
+func SetStaleHandler(handler func(packet *Packet)) Option {
+	return func(s *Settings) {
+		s.staleHandler = handler
+	}
+}
+
+// SetMaxInFlight injects the maximum number of commands awaiting response on
+// the same Conn to Settings. Other commands wait until the response to one of
+// them is received. Set it to 1 for servers which cannot handle pipelining.
+// Zero means no limit.
+func SetMaxInFlight(maxInFlight int) Option {
+	return func(s *Settings) {
+		s.maxInFlight = maxInFlight
+	}
+}
+
+// SetDialect injects the server dialect to Settings. It also injects the
+// dialect max command length, multi-packet strategy and heartbeat command,
+// so it overrides SetMaxCommandLen, SetMultiPacket and SetHeartbeatCommand
+// options passed before it.
+func SetDialect(dialect Dialect) Option {
+	return func(s *Settings) {
+		s.dialect = dialect
+		s.maxCommandLen = dialect.MaxCommandLen()
+		s.multiPacket = dialect.MultiPacket()
+		s.heartbeatCommand = dialect.HeartbeatCommand()
+	}
+}
+
+// SetTLSConfig injects TLS configuration to Settings. Dial and DialContext
+// connect over TLS when it is set. Server name is taken from the address if
+// config doesn't set ServerName. Set RootCAs to trust custom certificate
+// authorities, Certificates to send client certificates and VerifyConnection
+// to VerifySPKIPins to pin server certificates. Open ignores the option.
+func SetTLSConfig(config *tls.Config) Option {
+	return func(s *Settings) {
+		s.tlsConfig = config
+	}
+}
+
+// SetHeartbeat injects heartbeat interval and handler to Settings. When the
+// interval is positive, Conn sends the heartbeat command each time it has
+// received nothing from the server for the interval. The handler is called
+// with the round-trip latency or with the error after each heartbeat, it may
+// be nil. Conn is closed on heartbeat failure, so the following commands
+// fail with the error right away.
+// The handler is called from the heartbeat goroutine, so it must not block.
+func SetHeartbeat(interval time.Duration, handler func(latency time.Duration, err error)) Option {
+	return func(s *Settings) {
+		s.heartbeatInterval = interval
+		s.heartbeatHandler = handler
+	}
+}
+
+// SetHeartbeatCommand injects the harmless command sent by heartbeat to
+// Settings. Empty command means an empty SERVERDATA_RESPONSE_VALUE packet,
+// which is answered only by some servers, see Dialect.HeartbeatCommand.
+func SetHeartbeatCommand(command string) Option {
+	return func(s *Settings) {
+		s.heartbeatCommand = command
+	}
+}
+
+// SetLogger injects logger to Settings. Conn writes debug events for dial,
+// auth, each packet written and read, dialect quirks and errors to it.
+// Passwords are never logged.
+func SetLogger(logger *slog.Logger) Option {
+	return func(s *Settings) {
+		s.logger = logger
+	}
+}
+
+// SetMetrics injects metrics receiving measurements of dial, auth, command
+// execution, packet writes and reads to Settings. Client reports reconnects
+// to the metrics passed with SetConnOptions too.
+func SetMetrics(metrics Metrics) Option {
+	return func(s *Settings) {
+		if metrics == nil {
+			metrics = noMetrics{}
+		}
+
+		s.metrics = metrics
+	}
+}
+
+// SetTracer injects tracer starting spans around dial, auth and command
+// execution to Settings.
+func SetTracer(tracer Tracer) Option {
+	return func(s *Settings) {
+		if tracer == nil {
+			tracer = noTracer{}
+		}
+
+		s.tracer = tracer
+	}
+}
+
+// SetRateLimiter injects the limiter of command rate to Settings. The same
+// limiter should be passed to all connections to the server, so the limits
+// apply to them together. Commands are not limited by default.
+func SetRateLimiter(limiter *RateLimiter) Option {
+	return func(s *Settings) {
+		s.limiter = limiter
+	}
+}
+
+// SetRetryPolicy injects the policy of retrying failed commands to Settings.
+// Commands are not retried by default.
+func SetRetryPolicy(policy RetryPolicy) Option {
+	return func(s *Settings) {
+		s.retryPolicy = policy
+	}
+}
+
+// SetCircuitBreaker injects the circuit breaker of Dial and Execute to
+// Settings. The same breaker should be passed to all connections, so it
+// tracks failures of each server across them.
+func SetCircuitBreaker(breaker *CircuitBreaker) Option {
+	return func(s *Settings) {
+		s.breaker = breaker
+	}
+}
+
+// SetEncoding injects the character encoding of command and response bodies
+// to Settings. Commands and the password are encoded before sending and
+// responses are decoded after all their packets are received, so characters
+// split between packets are decoded correctly. The max command length is
+// checked against the encoded command. EncodingUTF8 is used by default.
+func SetEncoding(encoding Encoding) Option {
+	return func(s *Settings) {
+		if encoding == nil {
+			encoding = EncodingUTF8
+		}
+
+		s.encoding = encoding
+	}
+}
@@ -53,6 +53,9 @@ type Packet struct {
 	// RCON MockPassword for the RemoteServer, the command to be executed,
 	// or the RemoteServer's response to a request.
 	body []byte
+
+	// encoding decodes the body of packets received by Conn.
+	encoding Encoding
 }
 
 // NewPacket creates and initializes a new Packet using packetType,
@@ -70,8 +73,13 @@ func NewPacket(packetType int32, packetID int32, body string) *Packet {
 	}
 }
 
-// Body returns packet bytes body as a string.
+// Body returns packet bytes body as a string. The body of packets received
+// by Conn is decoded with the Conn encoding.
 func (packet *Packet) Body() string {
+	if packet.encoding != nil {
+		return packet.encoding.Decode(packet.body)
+	}
+
 	return string(packet.body)
 }
 
@@ -0,0 +1,216 @@
+package players
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// ErrUnexpectedResponse is returned when the response is not the player
+// list.
+var ErrUnexpectedResponse = errors.New("unexpected response")
+
+// arkRegexp matches the player line of ARK listplayers, e.g.
+// "0. Name, 76561198000000001".
+var arkRegexp = regexp.MustCompile(`^\d+\. (.*), ([0-9A-Za-z]+)$`)
+
+// ParseARK parses the response to listplayers command of ARK: Survival
+// Evolved and Ascended, which prints "0. Alice, 76561198000000001" line for
+// each player or "No Players Connected". PlatformID is Steam ID on ARK:
+// Survival Evolved and EOS ID on ARK: Survival Ascended.
+func ParseARK(s string) ([]Player, error) {
+	players := []Player{}
+
+	for _, line := range lines(s) {
+		line = strings.TrimSpace(line)
+
+		if line == "No Players Connected" {
+			return players, nil
+		}
+
+		match := arkRegexp.FindStringSubmatch(line)
+		if match == nil {
+			return nil, fmt.Errorf("players: %w: %q", ErrUnexpectedResponse, line)
+		}
+
+		players = append(players, Player{
+			Name:       match[1],
+			PlatformID: match[2],
+			Fields:     map[string]string{"name": match[1], "id": match[2]},
+		})
+	}
+
+	return players, nil
+}
+
+// ParsePalworld parses the response to ShowPlayers command of Palworld:
+//
+//	name,playeruid,steamid
+//	Alice,1234567890,76561198000000001
+//
+// Names are not quoted, so commas in names are kept.
+func ParsePalworld(s string) ([]Player, error) {
+	rows := lines(s)
+	if len(rows) == 0 || strings.TrimSpace(rows[0]) != "name,playeruid,steamid" {
+		return nil, fmt.Errorf("players: %w: %q", ErrUnexpectedResponse, s)
+	}
+
+	players := make([]Player, 0, len(rows)-1)
+
+	for _, line := range rows[1:] {
+		// The name is everything before the last two columns.
+		rest, steamID, ok := cutLast(line, ",")
+		name, uid, ok2 := cutLast(rest, ",")
+
+		if !ok || !ok2 {
+			return nil, fmt.Errorf("players: %w: %q", ErrUnexpectedResponse, line)
+		}
+
+		players = append(players, Player{
+			Name:       name,
+			ID:         uid,
+			PlatformID: steamID,
+			Fields:     map[string]string{"name": name, "playeruid": uid, "steamid": steamID},
+		})
+	}
+
+	return players, nil
+}
+
+// cutLast slices s around the last instance of sep.
+func cutLast(s string, sep string) (string, string, bool) {
+	if i := strings.LastIndex(s, sep); i >= 0 {
+		return s[:i], s[i+len(sep):], true
+	}
+
+	return s, "", false
+}
+
+// ParseConanExiles parses the response to ListPlayers command of Conan
+// Exiles:
+//
+//	Idx | Char name | Player name | User ID    | Platform ID       | Platform Name
+//	  0 | Conan     | Alice       | ABCDEF0123 | 76561198000000001 | Steam
+//
+// Name is the character name, ID is the Funcom user ID.
+func ParseConanExiles(s string) ([]Player, error) {
+	table, err := ParseDelimited(s, "|")
+	if err != nil {
+		return nil, err
+	}
+
+	if !hasColumns(table, "Char name") {
+		return nil, fmt.Errorf("players: %w: %q", ErrUnexpectedResponse, table.Header)
+	}
+
+	records := table.Records()
+	players := make([]Player, 0, len(records))
+
+	for _, record := range records {
+		players = append(players, Player{
+			Name:       record["Char name"],
+			ID:         record["User ID"],
+			PlatformID: record["Platform ID"],
+			Fields:     record,
+		})
+	}
+
+	return players, nil
+}
+
+// sevenDaysRegexp matches the player line of 7 Days to Die lp command after
+// the index, e.g. "id=171, Alice, pos=(1.0, 2.0, 3.0), ...".
+var sevenDaysRegexp = regexp.MustCompile(`^\d+\. id=(\d+), (.*?), pos=`)
+
+// Parse7DaysToDie parses the response to lp (listplayers) command of 7 Days
+// to Die, which prints a line for each player followed by the total:
+//
+//	id=171, Alice, pos=(-1.5, 61.0, 4.2), rot=(0.0, 90.0, 0.0), remote=True, health=100, deaths=0,
+//	zombies=5, players=0, score=5, level=3, pltfmid=Steam_76561198000000001, crossid=EOS_0002abc,
+//	ip=203.0.113.1, ping=45
+//
+// Each line is prefixed with the player index like "0. " and the list ends
+// with "Total of 1 in the game". PlatformID is taken from pltfmid or steamid
+// field of older versions.
+func Parse7DaysToDie(s string) ([]Player, error) {
+	players := []Player{}
+	valid := false
+
+	for _, line := range lines(s) {
+		line = strings.TrimSpace(line)
+
+		if strings.HasPrefix(line, "Total of ") {
+			valid = true
+
+			continue
+		}
+
+		match := sevenDaysRegexp.FindStringSubmatch(line)
+		if match == nil {
+			return nil, fmt.Errorf("players: %w: %q", ErrUnexpectedResponse, line)
+		}
+
+		fields := parseKeyValues(line[len(match[0])-len("pos="):])
+		fields["id"], fields["name"] = match[1], match[2]
+
+		player := Player{Name: match[2], ID: match[1], IP: fields["ip"], Fields: fields}
+		player.Ping, _ = strconv.Atoi(fields["ping"])
+
+		if player.PlatformID = fields["pltfmid"]; player.PlatformID == "" {
+			player.PlatformID = fields["steamid"]
+		}
+
+		players = append(players, player)
+		valid = true
+	}
+
+	if !valid {
+		return nil, fmt.Errorf("players: %w: %q", ErrUnexpectedResponse, s)
+	}
+
+	return players, nil
+}
+
+// parseKeyValues parses comma separated key=value pairs. Commas inside
+// parentheses don't separate pairs.
+func parseKeyValues(s string) map[string]string {
+	fields := make(map[string]string)
+	depth, start := 0, 0
+
+	for i := 0; i <= len(s); i++ {
+		if i < len(s) {
+			switch s[i] {
+			case '(':
+				depth++
+			case ')':
+				depth--
+			}
+
+			if s[i] != ',' || depth > 0 {
+				continue
+			}
+		}
+
+		if key, value, ok := strings.Cut(strings.TrimSpace(s[start:i]), "="); ok {
+			fields[key] = value
+		}
+
+		start = i + 1
+	}
+
+	return fields
+}
+
+// hasColumns reports whether the table has all the columns.
+func hasColumns(table *Table, names ...string) bool {
+	for _, name := range names {
+		if !slices.Contains(table.Header, name) {
+			return false
+		}
+	}
+
+	return true
+}
@@ -0,0 +1,137 @@
+package players_test
+
+import (
+	"errors"
+	"os"
+	"reflect"
+	"testing"
+
+	"github.com/gorcon/rcon/players"
+)
+
+// readFile returns the testdata file content.
+func readFile(t *testing.T, name string) string {
+	t.Helper()
+
+	data, err := os.ReadFile("testdata/" + name)
+	if err != nil {
+		t.Fatalf("got err %q, want %v", err, nil)
+	}
+
+	return string(data)
+}
+
+// summary is the Player without Fields.
+type summary struct {
+	Name, ID, PlatformID, IP string
+	Ping                     int
+}
+
+// summarize returns players without Fields.
+func summarize(list []players.Player) []summary {
+	result := make([]summary, 0, len(list))
+
+	for _, p := range list {
+		result = append(result, summary{Name: p.Name, ID: p.ID, PlatformID: p.PlatformID, IP: p.IP, Ping: p.Ping})
+	}
+
+	return result
+}
+
+func TestParsers(t *testing.T) {
+	tests := []struct {
+		name  string
+		file  string
+		parse func(s string) ([]players.Player, error)
+		want  []summary
+	}{
+		{"ark", "ark.txt", players.ParseARK, []summary{
+			{Name: "Alice", PlatformID: "76561198000000001"},
+			{Name: "Bob, Jr.", PlatformID: "0002b1234567890abcdef1234567890a"},
+		}},
+		{"palworld", "palworld.txt", players.ParsePalworld, []summary{
+			{Name: "Alice", ID: "1234567890", PlatformID: "76561198000000001"},
+			{Name: "Bob, the Builder", ID: "987654321", PlatformID: "76561198000000002"},
+		}},
+		{"conan exiles", "conanexiles.txt", players.ParseConanExiles, []summary{
+			{Name: "Conan", ID: "ABCDEF0123", PlatformID: "76561198000000001"},
+			{Name: "Valeria", ID: "0123ABCDEF", PlatformID: "76561198000000002"},
+		}},
+		{"7 days to die", "7dtd.txt", players.Parse7DaysToDie, []summary{
+			{Name: "Alice", ID: "171", PlatformID: "Steam_76561198000000001", IP: "203.0.113.1", Ping: 45},
+			{Name: "Bob", ID: "172", PlatformID: "76561198000000002", IP: "203.0.113.2", Ping: 120},
+		}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := tt.parse(readFile(t, tt.file))
+			if err != nil {
+				t.Fatalf("got err %q, want %v", err, nil)
+			}
+
+			if !reflect.DeepEqual(summarize(got), tt.want) {
+				t.Errorf("got %+v, want %+v", summarize(got), tt.want)
+			}
+		})
+	}
+}
+
+func TestParsers_Fields(t *testing.T) {
+	got, err := players.Parse7DaysToDie(readFile(t, "7dtd.txt"))
+	if err != nil {
+		t.Fatalf("got err %q, want %v", err, nil)
+	}
+
+	for key, want := range map[string]string{
+		"pos": "(-1.5, 61.0, 4.2)", "level": "3", "crossid": "EOS_0002abc", "name": "Alice", "id": "171",
+	} {
+		if got := got[0].Fields[key]; got != want {
+			t.Errorf("got %s %q, want %q", key, got, want)
+		}
+	}
+
+	conan, err := players.ParseConanExiles(readFile(t, "conanexiles.txt"))
+	if err != nil {
+		t.Fatalf("got err %q, want %v", err, nil)
+	}
+
+	if got := conan[1].Fields["Player name"]; got != "Bob" {
+		t.Errorf("got player name %q, want %q", got, "Bob")
+	}
+}
+
+func TestParsers_Empty(t *testing.T) {
+	tests := []struct {
+		name     string
+		response string
+		parse    func(s string) ([]players.Player, error)
+	}{
+		{"ark", "No Players Connected\n", players.ParseARK},
+		{"palworld", "name,playeruid,steamid\n", players.ParsePalworld},
+		{"conan exiles", "Idx | Char name | Player name | User ID | Platform ID | Platform Name\n", players.ParseConanExiles},
+		{"7 days to die", "Total of 0 in the game\n", players.Parse7DaysToDie},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := tt.parse(tt.response)
+			if err != nil || got == nil || len(got) != 0 {
+				t.Errorf("got %v, %v, want empty list", got, err)
+			}
+		})
+	}
+}
+
+func TestParsers_Unexpected(t *testing.T) {
+	for name, parse := range map[string]func(s string) ([]players.Player, error){
+		"ark":           players.ParseARK,
+		"palworld":      players.ParsePalworld,
+		"conan exiles":  players.ParseConanExiles,
+		"7 days to die": players.Parse7DaysToDie,
+	} {
+		if _, err := parse("Unknown command"); !errors.Is(err, players.ErrUnexpectedResponse) {
+			t.Errorf("%s: got err %q, want %q", name, err, players.ErrUnexpectedResponse)
+		}
+	}
+}
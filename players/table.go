@@ -0,0 +1,183 @@
+// Package players contains parsers of player lists returned by game servers
+// over RCON.
+package players
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"strings"
+	"unicode"
+)
+
+// ErrNoHeader is returned when the table has no header line.
+var ErrNoHeader = errors.New("table header not found")
+
+// Player is a player connected to the server. Fields not reported by the
+// game are left zero.
+type Player struct {
+	// Name is the player or character name.
+	Name string
+
+	// ID is the game specific player ID, e.g. entity ID or player UID.
+	ID string
+
+	// PlatformID is the Steam ID, EOS ID or another platform account ID.
+	PlatformID string
+
+	// Ping is the player latency in milliseconds.
+	Ping int
+
+	// IP is the player IP address.
+	IP string
+
+	// Fields are all fields reported by the game by their names.
+	Fields map[string]string
+}
+
+// Table is a parsed text table.
+type Table struct {
+	Header []string
+	Rows   [][]string
+}
+
+// Records returns the rows as maps by header names. Cells without header
+// are ignored, missing cells are empty.
+func (t *Table) Records() []map[string]string {
+	records := make([]map[string]string, 0, len(t.Rows))
+
+	for _, row := range t.Rows {
+		record := make(map[string]string, len(t.Header))
+
+		for i, name := range t.Header {
+			if i < len(row) {
+				record[name] = row[i]
+			} else {
+				record[name] = ""
+			}
+		}
+
+		records = append(records, record)
+	}
+
+	return records
+}
+
+// ParseDelimited parses the table with cells separated by sep, e.g. "," for
+// CSV-like output without quoting or "|" for pipe tables. The first non-empty
+// line is the header. Cells are trimmed, horizontal rules are skipped.
+func ParseDelimited(s string, sep string) (*Table, error) {
+	table := &Table{}
+
+	for _, line := range lines(s) {
+		if isRule(line) {
+			continue
+		}
+
+		cells := strings.Split(line, sep)
+		for i := range cells {
+			cells[i] = strings.TrimSpace(cells[i])
+		}
+
+		if table.Header == nil {
+			table.Header = cells
+		} else {
+			table.Rows = append(table.Rows, cells)
+		}
+	}
+
+	if table.Header == nil {
+		return nil, fmt.Errorf("players: %w", ErrNoHeader)
+	}
+
+	return table, nil
+}
+
+// ParseFixedWidth parses the table with columns aligned by spaces. Columns
+// start where the header names start, names are separated by at least two
+// spaces, so they may contain single spaces. Cells are trimmed, horizontal
+// rules are skipped.
+func ParseFixedWidth(s string) (*Table, error) {
+	table := &Table{}
+
+	var starts []int
+
+	for _, line := range lines(s) {
+		if isRule(line) {
+			continue
+		}
+
+		runes := []rune(line)
+
+		if table.Header == nil {
+			starts = columnStarts(runes)
+			table.Header = cutColumns(runes, starts)
+		} else {
+			table.Rows = append(table.Rows, cutColumns(runes, starts))
+		}
+	}
+
+	if table.Header == nil {
+		return nil, fmt.Errorf("players: %w", ErrNoHeader)
+	}
+
+	return table, nil
+}
+
+// columnStarts returns the start positions of the header names.
+func columnStarts(header []rune) []int {
+	var starts []int
+
+	for i, r := range header {
+		if unicode.IsSpace(r) {
+			continue
+		}
+
+		if len(starts) == 0 || (unicode.IsSpace(header[i-1]) && unicode.IsSpace(header[i-2])) {
+			starts = append(starts, i)
+		}
+	}
+
+	return starts
+}
+
+// cutColumns cuts the line into trimmed cells by the column starts. The last
+// column takes the rest of the line.
+func cutColumns(line []rune, starts []int) []string {
+	cells := make([]string, len(starts))
+
+	for i, start := range starts {
+		end := len(line)
+		if i+1 < len(starts) {
+			end = min(starts[i+1], len(line))
+		}
+
+		if start < end {
+			cells[i] = strings.TrimSpace(string(line[start:end]))
+		}
+	}
+
+	return cells
+}
+
+// lines returns non-empty lines of s without trailing spaces.
+func lines(s string) []string {
+	var result []string
+
+	scanner := bufio.NewScanner(strings.NewReader(s))
+	for scanner.Scan() {
+		if line := strings.TrimRightFunc(scanner.Text(), unicode.IsSpace); strings.TrimSpace(line) != "" {
+			result = append(result, line)
+		}
+	}
+
+	return result
+}
+
+// isRule reports whether the line is a horizontal rule consisting of
+// dashes and table borders, e.g. "----+-----".
+func isRule(line string) bool {
+	line = strings.TrimSpace(line)
+
+	return strings.Contains(line, "-") && strings.Trim(line, "-+=| ") == ""
+}
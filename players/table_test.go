@@ -0,0 +1,56 @@
+package players_test
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/gorcon/rcon/players"
+)
+
+func TestParseDelimited(t *testing.T) {
+	table, err := players.ParseDelimited("+----+------+\n| id | name |\n+----+------+\n| 1  | Alice |\n| 2 |\n", "|")
+	if err != nil {
+		t.Fatalf("got err %q, want %v", err, nil)
+	}
+
+	want := []map[string]string{
+		{"": "", "id": "1", "name": "Alice"},
+		{"": "", "id": "2", "name": ""},
+	}
+
+	if got := table.Records(); !reflect.DeepEqual(got, want) {
+		t.Errorf("got %v, want %v", got, want)
+	}
+
+	if _, err := players.ParseDelimited("\n \n", ","); !errors.Is(err, players.ErrNoHeader) {
+		t.Errorf("got err %q, want %q", err, players.ErrNoHeader)
+	}
+}
+
+func TestParseFixedWidth(t *testing.T) {
+	table, err := players.ParseFixedWidth(`
+  Name        Steam ID           Ping
+  ----------  -----------------  ----
+  Alice       76561198000000001    45
+  Bob Smith   76561198000000002   120
+  Ёжик        76561198000000003     7
+`)
+	if err != nil {
+		t.Fatalf("got err %q, want %v", err, nil)
+	}
+
+	wantHeader := []string{"Name", "Steam ID", "Ping"}
+	if !reflect.DeepEqual(table.Header, wantHeader) {
+		t.Errorf("got header %q, want %q", table.Header, wantHeader)
+	}
+
+	wantRows := [][]string{
+		{"Alice", "76561198000000001", "45"},
+		{"Bob Smith", "76561198000000002", "120"},
+		{"Ёжик", "76561198000000003", "7"},
+	}
+	if !reflect.DeepEqual(table.Rows, wantRows) {
+		t.Errorf("got rows %q, want %q", table.Rows, wantRows)
+	}
+}
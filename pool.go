@@ -0,0 +1,556 @@
+package rcon
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"sync"
+	"time"
+)
+
+const (
+	// DefaultPoolMaxIdle is the default maximum number of idle connections
+	// per server kept by Pool.
+	DefaultPoolMaxIdle = 2
+
+	// DefaultPoolMaintenanceInterval is the default interval between Pool
+	// health checks of idle connections.
+	DefaultPoolMaintenanceInterval = 30 * time.Second
+)
+
+// ErrPoolClosed is returned when getting connection from closed Pool.
+var ErrPoolClosed = errors.New("pool closed")
+
+// PoolSettings contains option to Pool.
+type PoolSettings struct {
+	options     []Option
+	maxOpen     int
+	minIdle     int
+	maxIdle     int
+	maxLifetime time.Duration
+	healthCheck string
+	interval    time.Duration
+}
+
+// DefaultPoolSettings provides default settings to Pool.
+var DefaultPoolSettings = PoolSettings{
+	maxIdle:  DefaultPoolMaxIdle,
+	interval: DefaultPoolMaintenanceInterval,
+}
+
+// PoolOption allows to inject settings to PoolSettings.
+type PoolOption func(s *PoolSettings)
+
+// SetPoolConnOptions injects Conn options which are used for each dial to
+// PoolSettings.
+func SetPoolConnOptions(options ...Option) PoolOption {
+	return func(s *PoolSettings) {
+		s.options = options
+	}
+}
+
+// SetPoolMaxOpen injects the maximum number of open connections per server
+// to PoolSettings. Get waits for a released connection when the limit is
+// reached. Zero means no limit.
+func SetPoolMaxOpen(maxOpen int) PoolOption {
+	return func(s *PoolSettings) {
+		s.maxOpen = maxOpen
+	}
+}
+
+// SetPoolMinIdle injects the minimum number of idle connections per server
+// to PoolSettings. Missing connections are dialed by the health check.
+func SetPoolMinIdle(minIdle int) PoolOption {
+	return func(s *PoolSettings) {
+		s.minIdle = minIdle
+	}
+}
+
+// SetPoolMaxIdle injects the maximum number of idle connections per server
+// to PoolSettings. Zero means no limit.
+func SetPoolMaxIdle(maxIdle int) PoolOption {
+	return func(s *PoolSettings) {
+		s.maxIdle = maxIdle
+	}
+}
+
+// SetPoolMaxLifetime injects the maximum amount of time a connection may be
+// reused to PoolSettings. Zero means connections are reused forever.
+func SetPoolMaxLifetime(lifetime time.Duration) PoolOption {
+	return func(s *PoolSettings) {
+		s.maxLifetime = lifetime
+	}
+}
+
+// SetPoolHealthCheck injects the interval between health checks of idle
+// connections and the harmless command which is executed on each of them
+// to PoolSettings. Only broken and expired connections are closed when the
+// command is empty.
+func SetPoolHealthCheck(command string, interval time.Duration) PoolOption {
+	return func(s *PoolSettings) {
+		s.healthCheck = command
+		s.interval = interval
+	}
+}
+
+// PoolStats contains Pool statistics.
+type PoolStats struct {
+	// Open is the number of open connections, both in use and idle.
+	Open int
+
+	// InUse is the number of connections currently in use.
+	InUse int
+
+	// Idle is the number of idle connections.
+	Idle int
+
+	// WaitCount is the total number of connections waited for.
+	WaitCount int64
+
+	// WaitDuration is the total time blocked waiting for a connection.
+	WaitDuration time.Duration
+}
+
+// PooledConn is a Conn borrowed from Pool. It must be returned to Pool by
+// Release when no longer needed.
+type PooledConn struct {
+	*Conn
+
+	server    *poolServer
+	createdAt time.Time
+	released  bool
+}
+
+// Release returns the connection to Pool. Broken or closed connections are
+// discarded.
+func (pc *PooledConn) Release() {
+	pc.server.pool.release(pc)
+}
+
+// poolKey identifies connections to the same server.
+type poolKey struct {
+	address  string
+	password string
+}
+
+// poolServer holds connections to the same server.
+type poolServer struct {
+	pool    *Pool
+	key     poolKey
+	idle    []*PooledConn
+	open    int
+	waiters []chan *PooledConn
+
+	waitCount    int64
+	waitDuration time.Duration
+}
+
+// stats returns the server connections statistics.
+func (s *poolServer) stats() PoolStats {
+	return PoolStats{
+		Open:         s.open,
+		InUse:        s.open - len(s.idle),
+		Idle:         len(s.idle),
+		WaitCount:    s.waitCount,
+		WaitDuration: s.waitDuration,
+	}
+}
+
+// Pool is a pool of authenticated connections keyed by server address and
+// password. Pool is safe for concurrent use by multiple goroutines.
+type Pool struct {
+	settings PoolSettings
+	quit     chan struct{}
+	wg       sync.WaitGroup
+
+	mu      sync.Mutex
+	servers map[poolKey]*poolServer
+	closed  bool
+}
+
+// NewPool creates a new Pool and starts its health check.
+func NewPool(options ...PoolOption) *Pool {
+	settings := DefaultPoolSettings
+	for _, option := range options {
+		option(&settings)
+	}
+
+	pool := &Pool{
+		settings: settings,
+		quit:     make(chan struct{}),
+		servers:  make(map[poolKey]*poolServer),
+	}
+
+	if settings.interval > 0 {
+		pool.wg.Add(1)
+
+		go pool.maintain()
+	}
+
+	return pool
+}
+
+// Get returns an idle connection to the server or dials a new one. When
+// the maximum number of open connections is reached, Get waits for a
+// released connection until ctx is done.
+func (p *Pool) Get(ctx context.Context, address string, password string) (*PooledConn, error) {
+	p.mu.Lock()
+
+	if p.closed {
+		p.mu.Unlock()
+
+		return nil, ErrPoolClosed
+	}
+
+	server := p.server(poolKey{address: address, password: password})
+
+	if pc := p.popIdle(server); pc != nil {
+		p.mu.Unlock()
+
+		return pc, nil
+	}
+
+	if p.settings.maxOpen == 0 || server.open < p.settings.maxOpen {
+		server.open++
+		p.mu.Unlock()
+
+		return p.dial(ctx, server)
+	}
+
+	waiter := make(chan *PooledConn, 1)
+	server.waiters = append(server.waiters, waiter)
+	server.waitCount++
+	start := time.Now()
+	p.mu.Unlock()
+
+	return p.wait(ctx, server, waiter, start)
+}
+
+// Execute executes command on a connection from Pool and releases it.
+func (p *Pool) Execute(ctx context.Context, address string, password string, command string) (string, error) {
+	pc, err := p.Get(ctx, address, password)
+	if err != nil {
+		return "", err
+	}
+
+	defer pc.Release()
+
+	return pc.ExecuteContext(ctx, command)
+}
+
+// Stats returns statistics of all the Pool connections.
+func (p *Pool) Stats() PoolStats {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	var stats PoolStats
+
+	for _, server := range p.servers {
+		stats = stats.add(server.stats())
+	}
+
+	return stats
+}
+
+// ServerStats returns statistics of the Pool connections to the server
+// address.
+func (p *Pool) ServerStats(address string) PoolStats {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	var stats PoolStats
+
+	for key, server := range p.servers {
+		if key.address == address {
+			stats = stats.add(server.stats())
+		}
+	}
+
+	return stats
+}
+
+// Close closes idle connections and stops the health check. Connections in
+// use are closed when released.
+func (p *Pool) Close() error {
+	p.mu.Lock()
+
+	if p.closed {
+		p.mu.Unlock()
+
+		return nil
+	}
+
+	p.closed = true
+	close(p.quit)
+
+	var idle []*PooledConn
+
+	for _, server := range p.servers {
+		idle = append(idle, server.idle...)
+		server.open -= len(server.idle)
+		server.idle = nil
+
+		for _, waiter := range server.waiters {
+			close(waiter)
+		}
+
+		server.waiters = nil
+	}
+
+	p.mu.Unlock()
+
+	p.wg.Wait()
+
+	var err error
+
+	for _, pc := range idle {
+		if err2 := pc.Conn.Close(); err2 != nil && err == nil {
+			err = err2
+		}
+	}
+
+	return err
+}
+
+// add returns the sum of stats.
+func (stats PoolStats) add(other PoolStats) PoolStats {
+	return PoolStats{
+		Open:         stats.Open + other.Open,
+		InUse:        stats.InUse + other.InUse,
+		Idle:         stats.Idle + other.Idle,
+		WaitCount:    stats.WaitCount + other.WaitCount,
+		WaitDuration: stats.WaitDuration + other.WaitDuration,
+	}
+}
+
+// server returns connections of the server with key. p.mu must be held.
+func (p *Pool) server(key poolKey) *poolServer {
+	server, ok := p.servers[key]
+	if !ok {
+		server = &poolServer{pool: p, key: key}
+		p.servers[key] = server
+	}
+
+	return server
+}
+
+// popIdle returns the most recently used healthy idle connection or nil.
+// Broken and expired connections are closed. p.mu must be held.
+func (p *Pool) popIdle(server *poolServer) *PooledConn {
+	for len(server.idle) > 0 {
+		pc := server.idle[len(server.idle)-1]
+		server.idle = server.idle[:len(server.idle)-1]
+
+		if p.usable(pc) {
+			pc.released = false
+
+			return pc
+		}
+
+		server.open--
+
+		go pc.Conn.Close()
+	}
+
+	return nil
+}
+
+// usable reports whether the connection can be reused.
+func (p *Pool) usable(pc *PooledConn) bool {
+	if p.settings.maxLifetime > 0 && time.Since(pc.createdAt) > p.settings.maxLifetime {
+		return false
+	}
+
+	return !pc.broken()
+}
+
+// dial opens a new connection to the server. The open connections counter
+// must be already increased for it.
+func (p *Pool) dial(ctx context.Context, server *poolServer) (*PooledConn, error) {
+	conn, err := DialContext(ctx, server.key.address, server.key.password, p.settings.options...)
+	if err != nil {
+		p.mu.Lock()
+		p.discard(server)
+		p.mu.Unlock()
+
+		return nil, err
+	}
+
+	return &PooledConn{Conn: conn, server: server, createdAt: time.Now()}, nil
+}
+
+// wait waits for a connection released to the waiter.
+func (p *Pool) wait(
+	ctx context.Context, server *poolServer, waiter chan *PooledConn, start time.Time,
+) (*PooledConn, error) {
+	select {
+	case pc, ok := <-waiter:
+		p.mu.Lock()
+		server.waitDuration += time.Since(start)
+		p.mu.Unlock()
+
+		if !ok {
+			return nil, ErrPoolClosed
+		}
+
+		if pc == nil {
+			// Connection was discarded, its slot is passed to the waiter.
+			return p.dial(ctx, server)
+		}
+
+		return pc, nil
+	case <-ctx.Done():
+		p.mu.Lock()
+		server.waitDuration += time.Since(start)
+
+		if i := slices.Index(server.waiters, waiter); i >= 0 {
+			server.waiters = slices.Delete(server.waiters, i, i+1)
+			p.mu.Unlock()
+
+			return nil, fmt.Errorf("rcon: %w", ctx.Err())
+		}
+
+		p.mu.Unlock()
+
+		// Connection or slot has been already passed to the waiter.
+		if pc, ok := <-waiter; ok {
+			if pc != nil {
+				p.release(pc)
+			} else {
+				p.mu.Lock()
+				p.discard(server)
+				p.mu.Unlock()
+			}
+		}
+
+		return nil, fmt.Errorf("rcon: %w", ctx.Err())
+	}
+}
+
+// release returns the connection to the idle connections or passes it to
+// a waiter.
+func (p *Pool) release(pc *PooledConn) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if pc.released {
+		return
+	}
+
+	pc.released = true
+	server := pc.server
+
+	maxIdle := p.settings.maxIdle > 0 && len(server.idle) >= p.settings.maxIdle
+	if p.closed || !p.usable(pc) || (len(server.waiters) == 0 && maxIdle) {
+		go pc.Conn.Close()
+
+		p.discard(server)
+
+		return
+	}
+
+	if len(server.waiters) > 0 {
+		pc.released = false
+		server.waiters[0] <- pc
+		server.waiters = server.waiters[1:]
+
+		return
+	}
+
+	server.idle = append(server.idle, pc)
+}
+
+// discard decreases the open connections counter and passes the freed slot
+// to a waiter. p.mu must be held.
+func (p *Pool) discard(server *poolServer) {
+	if len(server.waiters) > 0 && !p.closed {
+		server.waiters[0] <- nil
+		server.waiters = server.waiters[1:]
+
+		return
+	}
+
+	server.open--
+}
+
+// maintain runs health checks until Pool is closed.
+func (p *Pool) maintain() {
+	defer p.wg.Done()
+
+	ticker := time.NewTicker(p.settings.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			p.check()
+		case <-p.quit:
+			return
+		}
+	}
+}
+
+// check checks idle connections of all servers and dials missing ones.
+func (p *Pool) check() {
+	p.mu.Lock()
+
+	servers := make([]*poolServer, 0, len(p.servers))
+	for _, server := range p.servers {
+		servers = append(servers, server)
+	}
+
+	p.mu.Unlock()
+
+	for _, server := range servers {
+		p.checkServer(server)
+	}
+}
+
+// checkServer checks idle connections of the server and dials connections
+// up to the minimum number of idle connections.
+func (p *Pool) checkServer(server *poolServer) {
+	p.mu.Lock()
+	idle := server.idle
+	server.idle = nil
+
+	for _, pc := range idle {
+		pc.released = false
+	}
+
+	p.mu.Unlock()
+
+	for _, pc := range idle {
+		if p.settings.healthCheck != "" && p.usable(pc) {
+			ctx, cancel := context.WithTimeout(context.Background(), p.settings.interval)
+			if _, err := pc.ExecuteContext(ctx, p.settings.healthCheck); err != nil {
+				_ = pc.Conn.Close()
+			}
+
+			cancel()
+		}
+
+		p.release(pc)
+	}
+
+	for {
+		p.mu.Lock()
+
+		if p.closed || len(server.idle) >= p.settings.minIdle ||
+			(p.settings.maxOpen > 0 && server.open >= p.settings.maxOpen) {
+			p.mu.Unlock()
+
+			return
+		}
+
+		server.open++
+		p.mu.Unlock()
+
+		pc, err := p.dial(context.Background(), server)
+		if err != nil {
+			return
+		}
+
+		p.release(pc)
+	}
+}
@@ -0,0 +1,169 @@
+package rcon_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/gorcon/rcon"
+	"github.com/gorcon/rcon/rcontest"
+)
+
+func TestPool_Get(t *testing.T) {
+	server := rcontest.NewServer(
+		rcontest.SetSettings(rcontest.Settings{Password: "password"}),
+		rcontest.SetCommandHandler(commandHandler),
+	)
+	defer server.Close()
+
+	t.Run("reuse idle connection", func(t *testing.T) {
+		pool := rcon.NewPool()
+		defer pool.Close()
+
+		first, err := pool.Get(context.Background(), server.Addr(), "password")
+		if err != nil {
+			t.Fatalf("got err %q, want %v", err, nil)
+		}
+
+		first.Release()
+
+		second, err := pool.Get(context.Background(), server.Addr(), "password")
+		if err != nil {
+			t.Fatalf("got err %q, want %v", err, nil)
+		}
+		defer second.Release()
+
+		if first.Conn != second.Conn {
+			t.Error("got new connection, want idle one")
+		}
+
+		stats := pool.Stats()
+		if stats.Open != 1 || stats.InUse != 1 || stats.Idle != 0 {
+			t.Errorf("got stats %+v, want 1 open connection in use", stats)
+		}
+	})
+
+	t.Run("broken connection discarded", func(t *testing.T) {
+		pool := rcon.NewPool()
+		defer pool.Close()
+
+		pc, err := pool.Get(context.Background(), server.Addr(), "password")
+		if err != nil {
+			t.Fatalf("got err %q, want %v", err, nil)
+		}
+
+		pc.Close()
+		pc.Release()
+
+		if stats := pool.ServerStats(server.Addr()); stats.Open != 0 {
+			t.Errorf("got stats %+v, want no open connections", stats)
+		}
+	})
+
+	t.Run("max lifetime", func(t *testing.T) {
+		pool := rcon.NewPool(rcon.SetPoolMaxLifetime(10 * time.Millisecond))
+		defer pool.Close()
+
+		first, err := pool.Get(context.Background(), server.Addr(), "password")
+		if err != nil {
+			t.Fatalf("got err %q, want %v", err, nil)
+		}
+
+		first.Release()
+		time.Sleep(20 * time.Millisecond)
+
+		second, err := pool.Get(context.Background(), server.Addr(), "password")
+		if err != nil {
+			t.Fatalf("got err %q, want %v", err, nil)
+		}
+		defer second.Release()
+
+		if first.Conn == second.Conn {
+			t.Error("got expired connection, want new one")
+		}
+	})
+
+	t.Run("wait for released connection", func(t *testing.T) {
+		pool := rcon.NewPool(rcon.SetPoolMaxOpen(1))
+		defer pool.Close()
+
+		first, err := pool.Get(context.Background(), server.Addr(), "password")
+		if err != nil {
+			t.Fatalf("got err %q, want %v", err, nil)
+		}
+
+		time.AfterFunc(50*time.Millisecond, first.Release)
+
+		second, err := pool.Get(context.Background(), server.Addr(), "password")
+		if err != nil {
+			t.Fatalf("got err %q, want %v", err, nil)
+		}
+		defer second.Release()
+
+		if first.Conn != second.Conn {
+			t.Error("got new connection, want released one")
+		}
+
+		stats := pool.Stats()
+		if stats.WaitCount != 1 || stats.WaitDuration <= 0 {
+			t.Errorf("got stats %+v, want 1 wait", stats)
+		}
+	})
+
+	t.Run("wait timeout", func(t *testing.T) {
+		pool := rcon.NewPool(rcon.SetPoolMaxOpen(1))
+		defer pool.Close()
+
+		first, err := pool.Get(context.Background(), server.Addr(), "password")
+		if err != nil {
+			t.Fatalf("got err %q, want %v", err, nil)
+		}
+		defer first.Release()
+
+		ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+		defer cancel()
+
+		if _, err := pool.Get(ctx, server.Addr(), "password"); !errors.Is(err, context.DeadlineExceeded) {
+			t.Errorf("got err %q, want %q", err, context.DeadlineExceeded)
+		}
+	})
+
+	t.Run("closed", func(t *testing.T) {
+		pool := rcon.NewPool()
+		pool.Close()
+
+		if _, err := pool.Get(context.Background(), server.Addr(), "password"); !errors.Is(err, rcon.ErrPoolClosed) {
+			t.Errorf("got err %q, want %q", err, rcon.ErrPoolClosed)
+		}
+	})
+}
+
+func TestPool_Execute(t *testing.T) {
+	server := rcontest.NewServer(
+		rcontest.SetSettings(rcontest.Settings{Password: "password"}),
+		rcontest.SetCommandHandler(commandHandler),
+	)
+	defer server.Close()
+
+	t.Run("health check with min idle", func(t *testing.T) {
+		pool := rcon.NewPool(rcon.SetPoolMinIdle(2), rcon.SetPoolHealthCheck("help", 10*time.Millisecond))
+		defer pool.Close()
+
+		result, err := pool.Execute(context.Background(), server.Addr(), "password", "help")
+		if err != nil {
+			t.Fatalf("got err %q, want %v", err, nil)
+		}
+
+		if resultWant := "lorem ipsum dolor sit amet"; result != resultWant {
+			t.Fatalf("got result %q, want %q", result, resultWant)
+		}
+
+		time.Sleep(100 * time.Millisecond)
+
+		// Idle connections are reported in use while they are checked.
+		if stats := pool.Stats(); stats.Open != 2 {
+			t.Errorf("got stats %+v, want 2 open connections", stats)
+		}
+	})
+}
@@ -0,0 +1,257 @@
+package rcon
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"maps"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// DefaultPrometheusBuckets are the default histogram buckets of
+// PrometheusMetrics in seconds.
+var DefaultPrometheusBuckets = []float64{.005, .01, .025, .05, .1, .25, .5, 1, 2.5, 5, 10}
+
+// PrometheusMetrics is Metrics which collects measurements in memory and
+// renders them in Prometheus text exposition format. It implements
+// http.Handler, so it can be mounted as a metrics endpoint.
+type PrometheusMetrics struct {
+	buckets []float64
+
+	mu           sync.Mutex
+	durations    map[durationKey]*histogram
+	errors       map[errorKey]uint64
+	bytesWritten map[string]uint64
+	bytesRead    map[string]uint64
+	reconnects   map[string]uint64
+}
+
+// durationKey identifies a duration histogram.
+type durationKey struct {
+	name   string
+	server string
+}
+
+// errorKey identifies an error counter.
+type errorKey struct {
+	server    string
+	operation string
+	errorType string
+}
+
+// histogram is a cumulative histogram.
+type histogram struct {
+	counts []uint64
+	sum    float64
+	count  uint64
+}
+
+// prometheusDurations are the names and help of duration histograms.
+var prometheusDurations = [][2]string{
+	{"rcon_dial_duration_seconds", "Time spent connecting to the server."},
+	{"rcon_auth_duration_seconds", "Time spent authenticating on the server."},
+	{"rcon_command_duration_seconds", "Time spent executing commands."},
+}
+
+// NewPrometheusMetrics creates PrometheusMetrics with the histogram buckets
+// in seconds. DefaultPrometheusBuckets are used if no buckets are given.
+func NewPrometheusMetrics(buckets ...float64) *PrometheusMetrics {
+	if len(buckets) == 0 {
+		buckets = DefaultPrometheusBuckets
+	}
+
+	buckets = slices.Clone(buckets)
+	slices.Sort(buckets)
+
+	return &PrometheusMetrics{
+		buckets:      buckets,
+		durations:    make(map[durationKey]*histogram),
+		errors:       make(map[errorKey]uint64),
+		bytesWritten: make(map[string]uint64),
+		bytesRead:    make(map[string]uint64),
+		reconnects:   make(map[string]uint64),
+	}
+}
+
+// ObserveDial implements Metrics.
+func (m *PrometheusMetrics) ObserveDial(address string, duration time.Duration, err error) {
+	m.observe("rcon_dial_duration_seconds", "dial", address, duration, err)
+}
+
+// ObserveAuth implements Metrics.
+func (m *PrometheusMetrics) ObserveAuth(address string, duration time.Duration, err error) {
+	m.observe("rcon_auth_duration_seconds", "auth", address, duration, err)
+}
+
+// ObserveExecute implements Metrics.
+func (m *PrometheusMetrics) ObserveExecute(address string, duration time.Duration, err error) {
+	m.observe("rcon_command_duration_seconds", "execute", address, duration, err)
+}
+
+// ObserveWrite implements Metrics.
+func (m *PrometheusMetrics) ObserveWrite(address string, n int, err error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.bytesWritten[address] += uint64(n)
+
+	if err != nil {
+		m.errors[errorKey{server: address, operation: "write", errorType: ErrorType(err)}]++
+	}
+}
+
+// ObserveRead implements Metrics.
+func (m *PrometheusMetrics) ObserveRead(address string, n int, err error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.bytesRead[address] += uint64(n)
+
+	if err != nil {
+		m.errors[errorKey{server: address, operation: "read", errorType: ErrorType(err)}]++
+	}
+}
+
+// ObserveReconnect implements Metrics.
+func (m *PrometheusMetrics) ObserveReconnect(address string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.reconnects[address]++
+}
+
+// WriteTo implements io.WriterTo for writing the metrics in Prometheus text
+// exposition format to w.
+func (m *PrometheusMetrics) WriteTo(w io.Writer) (int64, error) {
+	var buffer bytes.Buffer
+
+	m.mu.Lock()
+
+	for _, d := range prometheusDurations {
+		m.writeHistograms(&buffer, d[0], d[1])
+	}
+
+	writeCounterHeader(&buffer, "rcon_errors_total", "Number of errors by operation and error type.")
+
+	for _, key := range slices.SortedFunc(maps.Keys(m.errors), compareErrorKeys) {
+		fmt.Fprintf(&buffer, "rcon_errors_total{server=%s,operation=%s,type=%s} %d\n",
+			quote(key.server), quote(key.operation), quote(key.errorType), m.errors[key])
+	}
+
+	writeCounters(&buffer, "rcon_written_bytes_total", "Number of bytes written to the server.", m.bytesWritten)
+	writeCounters(&buffer, "rcon_read_bytes_total", "Number of bytes read from the server.", m.bytesRead)
+	writeCounters(&buffer, "rcon_reconnects_total", "Number of reconnects to the server.", m.reconnects)
+
+	m.mu.Unlock()
+
+	return buffer.WriteTo(w)
+}
+
+// ServeHTTP implements http.Handler.
+func (m *PrometheusMetrics) ServeHTTP(w http.ResponseWriter, _ *http.Request) {
+	w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+
+	_, _ = m.WriteTo(w)
+}
+
+// observe updates the duration histogram and the error counter.
+func (m *PrometheusMetrics) observe(name string, operation string, address string, duration time.Duration, err error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	key := durationKey{name: name, server: address}
+
+	h, ok := m.durations[key]
+	if !ok {
+		h = &histogram{counts: make([]uint64, len(m.buckets))}
+		m.durations[key] = h
+	}
+
+	seconds := duration.Seconds()
+
+	for i, bucket := range m.buckets {
+		if seconds <= bucket {
+			h.counts[i]++
+		}
+	}
+
+	h.sum += seconds
+	h.count++
+
+	if err != nil {
+		m.errors[errorKey{server: address, operation: operation, errorType: ErrorType(err)}]++
+	}
+}
+
+// writeHistograms writes the histograms with the name. m.mu must be held.
+func (m *PrometheusMetrics) writeHistograms(buffer *bytes.Buffer, name string, help string) {
+	fmt.Fprintf(buffer, "# HELP %s %s\n# TYPE %s histogram\n", name, help, name)
+
+	var servers []string
+
+	for key := range m.durations {
+		if key.name == name {
+			servers = append(servers, key.server)
+		}
+	}
+
+	slices.Sort(servers)
+
+	for _, server := range servers {
+		h := m.durations[durationKey{name: name, server: server}]
+		label := quote(server)
+
+		for i, bucket := range m.buckets {
+			fmt.Fprintf(buffer, "%s_bucket{server=%s,le=\"%s\"} %d\n", name, label, formatFloat(bucket), h.counts[i])
+		}
+
+		fmt.Fprintf(buffer, "%s_bucket{server=%s,le=\"+Inf\"} %d\n", name, label, h.count)
+		fmt.Fprintf(buffer, "%s_sum{server=%s} %s\n", name, label, formatFloat(h.sum))
+		fmt.Fprintf(buffer, "%s_count{server=%s} %d\n", name, label, h.count)
+	}
+}
+
+// writeCounterHeader writes HELP and TYPE lines of the counter.
+func writeCounterHeader(buffer *bytes.Buffer, name string, help string) {
+	fmt.Fprintf(buffer, "# HELP %s %s\n# TYPE %s counter\n", name, help, name)
+}
+
+// writeCounters writes the counters by server.
+func writeCounters(buffer *bytes.Buffer, name string, help string, counters map[string]uint64) {
+	writeCounterHeader(buffer, name, help)
+
+	for _, server := range slices.Sorted(maps.Keys(counters)) {
+		fmt.Fprintf(buffer, "%s{server=%s} %d\n", name, quote(server), counters[server])
+	}
+}
+
+// compareErrorKeys orders error counters by labels.
+func compareErrorKeys(a, b errorKey) int {
+	if c := strings.Compare(a.server, b.server); c != 0 {
+		return c
+	}
+
+	if c := strings.Compare(a.operation, b.operation); c != 0 {
+		return c
+	}
+
+	return strings.Compare(a.errorType, b.errorType)
+}
+
+// labelEscaper escapes label values as required by the text format.
+var labelEscaper = strings.NewReplacer(`\`, `\\`, "\n", `\n`, `"`, `\"`)
+
+// quote returns the quoted label value.
+func quote(value string) string {
+	return `"` + labelEscaper.Replace(value) + `"`
+}
+
+// formatFloat formats the sample value.
+func formatFloat(value float64) string {
+	return strconv.FormatFloat(value, 'g', -1, 64)
+}
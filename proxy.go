@@ -0,0 +1,380 @@
+package rcon
+
+import (
+	"bufio"
+	"context"
+	"encoding/base64"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+)
+
+var (
+	// ErrProxyAuthFailed is returned when the proxy server rejects the
+	// credentials.
+	ErrProxyAuthFailed = errors.New("proxy authentication failed")
+
+	// ErrProxyRejected is returned when the proxy server refuses to connect
+	// to the target address.
+	ErrProxyRejected = errors.New("proxy rejected connection")
+
+	// ErrProxyScheme is returned by ProxyDialer for unsupported proxy URL
+	// scheme.
+	ErrProxyScheme = errors.New("unsupported proxy scheme")
+)
+
+// SOCKS5 protocol constants from RFC 1928 and RFC 1929.
+const (
+	socks5Version          = 0x05
+	socks5AuthNone         = 0x00
+	socks5AuthPassword     = 0x02
+	socks5AuthNoAcceptable = 0xff
+	socks5PasswordVersion  = 0x01
+	socks5CommandConnect   = 0x01
+	socks5AddrIPv4         = 0x01
+	socks5AddrDomain       = 0x03
+	socks5AddrIPv6         = 0x04
+)
+
+// socks5Replies are the messages of SOCKS5 reply codes.
+var socks5Replies = map[byte]string{
+	0x01: "general SOCKS server failure",
+	0x02: "connection not allowed by ruleset",
+	0x03: "network unreachable",
+	0x04: "host unreachable",
+	0x05: "connection refused",
+	0x06: "TTL expired",
+	0x07: "command not supported",
+	0x08: "address type not supported",
+}
+
+// SOCKS5Dialer connects to the server through SOCKS5 proxy. The target host
+// name is resolved by the proxy.
+type SOCKS5Dialer struct {
+	// Address is the proxy server address.
+	Address string
+
+	// Username and Password are used for username/password authentication
+	// if Username is not empty.
+	Username string
+	Password string
+
+	// Forward connects to the proxy server. net.Dialer is used if nil.
+	Forward Dialer
+}
+
+// DialContext implements Dialer.
+func (d *SOCKS5Dialer) DialContext(ctx context.Context, network string, address string) (net.Conn, error) {
+	conn, err := dialForward(ctx, d.Forward, network, d.Address)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := negotiate(ctx, conn, func() error { return d.connect(conn, address) }); err != nil {
+		return nil, err
+	}
+
+	return conn, nil
+}
+
+// connect authenticates on the proxy and requests connection to address.
+func (d *SOCKS5Dialer) connect(conn net.Conn, address string) error {
+	request, err := socks5Request(address)
+	if err != nil {
+		return err
+	}
+
+	method := byte(socks5AuthNone)
+	if d.Username != "" {
+		method = socks5AuthPassword
+	}
+
+	if _, err := conn.Write([]byte{socks5Version, 1, method}); err != nil {
+		return fmt.Errorf("socks5: %w", err)
+	}
+
+	reply := make([]byte, 2)
+	if _, err := io.ReadFull(conn, reply); err != nil {
+		return fmt.Errorf("socks5: %w", err)
+	}
+
+	switch {
+	case reply[0] != socks5Version:
+		return fmt.Errorf("%w: unexpected socks version %d", ErrProxyRejected, reply[0])
+	case reply[1] == socks5AuthNoAcceptable || reply[1] != method:
+		return fmt.Errorf("%w: no acceptable authentication methods", ErrProxyAuthFailed)
+	case method == socks5AuthPassword:
+		if err := d.authenticate(conn); err != nil {
+			return err
+		}
+	}
+
+	if _, err := conn.Write(request); err != nil {
+		return fmt.Errorf("socks5: %w", err)
+	}
+
+	return socks5Reply(conn)
+}
+
+// authenticate performs username/password authentication.
+func (d *SOCKS5Dialer) authenticate(conn net.Conn) error {
+	if len(d.Username) > 255 || len(d.Password) > 255 {
+		return fmt.Errorf("%w: username or password too long", ErrProxyAuthFailed)
+	}
+
+	request := []byte{socks5PasswordVersion, byte(len(d.Username))}
+	request = append(request, d.Username...)
+	request = append(request, byte(len(d.Password)))
+	request = append(request, d.Password...)
+
+	if _, err := conn.Write(request); err != nil {
+		return fmt.Errorf("socks5: %w", err)
+	}
+
+	reply := make([]byte, 2)
+	if _, err := io.ReadFull(conn, reply); err != nil {
+		return fmt.Errorf("socks5: %w", err)
+	}
+
+	if reply[1] != 0 {
+		return ErrProxyAuthFailed
+	}
+
+	return nil
+}
+
+// socks5Request returns CONNECT request to address.
+func socks5Request(address string) ([]byte, error) {
+	host, portStr, err := net.SplitHostPort(address)
+	if err != nil {
+		return nil, fmt.Errorf("socks5: %w", err)
+	}
+
+	port, err := strconv.ParseUint(portStr, 10, 16)
+	if err != nil {
+		return nil, fmt.Errorf("socks5: invalid port %q", portStr)
+	}
+
+	request := []byte{socks5Version, socks5CommandConnect, 0}
+
+	switch ip := net.ParseIP(host); {
+	case ip.To4() != nil:
+		request = append(request, socks5AddrIPv4)
+		request = append(request, ip.To4()...)
+	case ip != nil:
+		request = append(request, socks5AddrIPv6)
+		request = append(request, ip...)
+	case len(host) > 255:
+		return nil, fmt.Errorf("socks5: host name too long %q", host)
+	default:
+		request = append(request, socks5AddrDomain, byte(len(host)))
+		request = append(request, host...)
+	}
+
+	return binary.BigEndian.AppendUint16(request, uint16(port)), nil
+}
+
+// socks5Reply reads the reply to CONNECT request.
+func socks5Reply(conn net.Conn) error {
+	reply := make([]byte, 4)
+	if _, err := io.ReadFull(conn, reply); err != nil {
+		return fmt.Errorf("socks5: %w", err)
+	}
+
+	if reply[1] != 0 {
+		message, ok := socks5Replies[reply[1]]
+		if !ok {
+			message = "unknown error " + strconv.Itoa(int(reply[1]))
+		}
+
+		return fmt.Errorf("%w: %s", ErrProxyRejected, message)
+	}
+
+	// Discard the bound address and port.
+	var size int64
+
+	switch reply[3] {
+	case socks5AddrIPv4:
+		size = net.IPv4len + 2
+	case socks5AddrIPv6:
+		size = net.IPv6len + 2
+	case socks5AddrDomain:
+		length := make([]byte, 1)
+		if _, err := io.ReadFull(conn, length); err != nil {
+			return fmt.Errorf("socks5: %w", err)
+		}
+
+		size = int64(length[0]) + 2
+	default:
+		return fmt.Errorf("%w: unknown address type %d", ErrProxyRejected, reply[3])
+	}
+
+	if _, err := io.CopyN(io.Discard, conn, size); err != nil {
+		return fmt.Errorf("socks5: %w", err)
+	}
+
+	return nil
+}
+
+// HTTPProxyDialer connects to the server through HTTP proxy with CONNECT
+// method.
+type HTTPProxyDialer struct {
+	// Address is the proxy server address.
+	Address string
+
+	// Username and Password are sent with Basic authentication if Username
+	// is not empty.
+	Username string
+	Password string
+
+	// Header contains additional headers sent with CONNECT request.
+	Header http.Header
+
+	// Forward connects to the proxy server. net.Dialer is used if nil.
+	Forward Dialer
+}
+
+// DialContext implements Dialer.
+func (d *HTTPProxyDialer) DialContext(ctx context.Context, network string, address string) (net.Conn, error) {
+	conn, err := dialForward(ctx, d.Forward, network, d.Address)
+	if err != nil {
+		return nil, err
+	}
+
+	var reader *bufio.Reader
+
+	if err := negotiate(ctx, conn, func() (err error) {
+		reader, err = d.connect(conn, address)
+
+		return err
+	}); err != nil {
+		return nil, err
+	}
+
+	if reader.Buffered() > 0 {
+		// The server has already sent data after the proxy response.
+		return &bufferedConn{Conn: conn, r: reader}, nil
+	}
+
+	return conn, nil
+}
+
+// connect sends CONNECT request to address and reads the proxy response.
+func (d *HTTPProxyDialer) connect(conn net.Conn, address string) (*bufio.Reader, error) {
+	request := &http.Request{
+		Method: http.MethodConnect,
+		URL:    &url.URL{Opaque: address},
+		Host:   address,
+		Header: d.Header.Clone(),
+	}
+
+	if request.Header == nil {
+		request.Header = make(http.Header)
+	}
+
+	if d.Username != "" {
+		credentials := base64.StdEncoding.EncodeToString([]byte(d.Username + ":" + d.Password))
+		request.Header.Set("Proxy-Authorization", "Basic "+credentials)
+	}
+
+	if err := request.Write(conn); err != nil {
+		return nil, fmt.Errorf("http proxy: %w", err)
+	}
+
+	reader := bufio.NewReader(conn)
+
+	response, err := http.ReadResponse(reader, request)
+	if err != nil {
+		return nil, fmt.Errorf("http proxy: %w", err)
+	}
+
+	_ = response.Body.Close()
+
+	switch response.StatusCode {
+	case http.StatusOK:
+		return reader, nil
+	case http.StatusProxyAuthRequired:
+		return nil, ErrProxyAuthFailed
+	default:
+		return nil, fmt.Errorf("%w: %s", ErrProxyRejected, response.Status)
+	}
+}
+
+// bufferedConn is net.Conn which reads data buffered by bufio.Reader first.
+type bufferedConn struct {
+	net.Conn
+	r *bufio.Reader
+}
+
+// Read implements io.Reader.
+func (c *bufferedConn) Read(p []byte) (int, error) {
+	return c.r.Read(p)
+}
+
+// ProxyDialer returns Dialer for proxy URL with socks5, socks5h or http
+// scheme. Credentials are taken from the URL user info.
+func ProxyDialer(proxyURL *url.URL, forward Dialer) (Dialer, error) {
+	username := proxyURL.User.Username()
+	password, _ := proxyURL.User.Password()
+
+	switch proxyURL.Scheme {
+	case "socks5", "socks5h":
+		return &SOCKS5Dialer{Address: proxyURL.Host, Username: username, Password: password, Forward: forward}, nil
+	case "http":
+		return &HTTPProxyDialer{Address: proxyURL.Host, Username: username, Password: password, Forward: forward}, nil
+	default:
+		return nil, fmt.Errorf("%w: %q", ErrProxyScheme, proxyURL.Scheme)
+	}
+}
+
+// dialForward connects to the proxy server with forward or net.Dialer.
+func dialForward(ctx context.Context, forward Dialer, network string, address string) (net.Conn, error) {
+	if forward == nil {
+		forward = &net.Dialer{}
+	}
+
+	conn, err := forward.DialContext(ctx, network, address)
+	if err != nil {
+		return nil, fmt.Errorf("dial proxy: %w", err)
+	}
+
+	return conn, nil
+}
+
+// negotiate runs the proxy handshake within ctx. The conn is closed if the
+// handshake fails.
+func negotiate(ctx context.Context, conn net.Conn, handshake func() error) error {
+	if deadline, ok := ctx.Deadline(); ok {
+		_ = conn.SetDeadline(deadline)
+	}
+
+	stop := context.AfterFunc(ctx, func() {
+		_ = conn.SetDeadline(time.Unix(1, 0))
+	})
+
+	err := handshake()
+
+	if !stop() && err == nil {
+		err = ctx.Err()
+	} else if err == nil {
+		err = conn.SetDeadline(time.Time{})
+	}
+
+	if err != nil {
+		_ = conn.Close()
+
+		if ctxErr := ctx.Err(); ctxErr != nil {
+			return ctxErr
+		}
+
+		return err
+	}
+
+	return nil
+}
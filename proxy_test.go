@@ -0,0 +1,237 @@
+package rcon_test
+
+import (
+	"encoding/binary"
+	"errors"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strconv"
+	"testing"
+
+	"github.com/gorcon/rcon"
+	"github.com/gorcon/rcon/rcontest"
+)
+
+// pipe copies data between the client and the target conns until one of them
+// is closed.
+func pipe(client net.Conn, target net.Conn) {
+	go func() {
+		io.Copy(target, client)
+		target.Close()
+	}()
+
+	io.Copy(client, target)
+	client.Close()
+}
+
+// newSOCKS5Proxy starts minimal SOCKS5 proxy server requiring username and
+// password if username is not empty.
+func newSOCKS5Proxy(t *testing.T, username string, password string) string {
+	t.Helper()
+
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	t.Cleanup(func() { l.Close() })
+
+	go func() {
+		for {
+			conn, err := l.Accept()
+			if err != nil {
+				return
+			}
+
+			go serveSOCKS5(conn, username, password)
+		}
+	}()
+
+	return l.Addr().String()
+}
+
+func serveSOCKS5(conn net.Conn, username string, password string) {
+	header := make([]byte, 2)
+	io.ReadFull(conn, header)
+
+	methods := make([]byte, header[1])
+	io.ReadFull(conn, methods)
+
+	if username == "" {
+		conn.Write([]byte{0x05, 0x00})
+	} else {
+		conn.Write([]byte{0x05, 0x02})
+
+		// Version and username length.
+		io.ReadFull(conn, header)
+
+		user := make([]byte, header[1])
+		io.ReadFull(conn, user)
+		io.ReadFull(conn, header[:1])
+
+		pass := make([]byte, header[0])
+		io.ReadFull(conn, pass)
+
+		if string(user) != username || string(pass) != password {
+			conn.Write([]byte{0x01, 0x01})
+			conn.Close()
+
+			return
+		}
+
+		conn.Write([]byte{0x01, 0x00})
+	}
+
+	// Version, command, reserved and IPv4 address type are expected.
+	request := make([]byte, 10)
+	io.ReadFull(conn, request)
+
+	port := strconv.Itoa(int(binary.BigEndian.Uint16(request[8:])))
+
+	target, err := net.Dial("tcp", net.JoinHostPort(net.IP(request[4:8]).String(), port))
+	if err != nil {
+		conn.Write([]byte{0x05, 0x05, 0x00, 0x01, 0, 0, 0, 0, 0, 0})
+		conn.Close()
+
+		return
+	}
+
+	conn.Write([]byte{0x05, 0x00, 0x00, 0x01, 0, 0, 0, 0, 0, 0})
+	pipe(conn, target)
+}
+
+// newHTTPProxy starts HTTP proxy server supporting CONNECT method and
+// requiring Basic authentication if username is not empty.
+func newHTTPProxy(t *testing.T, username string, password string) string {
+	t.Helper()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodConnect {
+			w.WriteHeader(http.StatusMethodNotAllowed)
+
+			return
+		}
+
+		if username != "" {
+			r.Header.Set("Authorization", r.Header.Get("Proxy-Authorization"))
+
+			if u, p, ok := r.BasicAuth(); !ok || u != username || p != password {
+				w.WriteHeader(http.StatusProxyAuthRequired)
+
+				return
+			}
+		}
+
+		target, err := net.Dial("tcp", r.Host)
+		if err != nil {
+			w.WriteHeader(http.StatusBadGateway)
+
+			return
+		}
+
+		conn, _, err := http.NewResponseController(w).Hijack()
+		if err != nil {
+			target.Close()
+
+			return
+		}
+
+		conn.Write([]byte("HTTP/1.1 200 Connection established\r\n\r\n"))
+		pipe(conn, target)
+	}))
+
+	t.Cleanup(server.Close)
+
+	return server.Listener.Addr().String()
+}
+
+func TestSetDialer(t *testing.T) {
+	server := rcontest.NewServer(
+		rcontest.SetSettings(rcontest.Settings{Password: "password"}),
+		rcontest.SetCommandHandler(commandHandler),
+	)
+	defer server.Close()
+
+	execute := func(t *testing.T, dialer rcon.Dialer) error {
+		t.Helper()
+
+		conn, err := rcon.Dial(server.Addr(), "password", rcon.SetDialer(dialer))
+		if err != nil {
+			return err
+		}
+		defer conn.Close()
+
+		result, err := conn.Execute("help")
+		if err != nil {
+			t.Fatalf("got err %q, want %v", err, nil)
+		}
+
+		if resultWant := "lorem ipsum dolor sit amet"; result != resultWant {
+			t.Fatalf("got result %q, want %q", result, resultWant)
+		}
+
+		return nil
+	}
+
+	t.Run("net dialer", func(t *testing.T) {
+		dialer := &net.Dialer{LocalAddr: &net.TCPAddr{IP: net.IPv4(127, 0, 0, 1)}, KeepAlive: -1}
+
+		if err := execute(t, dialer); err != nil {
+			t.Fatalf("got err %q, want %v", err, nil)
+		}
+	})
+
+	t.Run("socks5", func(t *testing.T) {
+		dialer := &rcon.SOCKS5Dialer{Address: newSOCKS5Proxy(t, "", "")}
+
+		if err := execute(t, dialer); err != nil {
+			t.Fatalf("got err %q, want %v", err, nil)
+		}
+	})
+
+	t.Run("socks5 with password", func(t *testing.T) {
+		address := newSOCKS5Proxy(t, "user", "secret")
+
+		if err := execute(t, &rcon.SOCKS5Dialer{Address: address, Username: "user", Password: "secret"}); err != nil {
+			t.Fatalf("got err %q, want %v", err, nil)
+		}
+
+		err := execute(t, &rcon.SOCKS5Dialer{Address: address, Username: "user", Password: "wrong"})
+		if !errors.Is(err, rcon.ErrProxyAuthFailed) {
+			t.Errorf("got err %q, want %q", err, rcon.ErrProxyAuthFailed)
+		}
+	})
+
+	t.Run("http connect", func(t *testing.T) {
+		dialer, err := rcon.ProxyDialer(&url.URL{Scheme: "http", Host: newHTTPProxy(t, "", "")}, nil)
+		if err != nil {
+			t.Fatalf("got err %q, want %v", err, nil)
+		}
+
+		if err := execute(t, dialer); err != nil {
+			t.Fatalf("got err %q, want %v", err, nil)
+		}
+	})
+
+	t.Run("http connect with password", func(t *testing.T) {
+		address := newHTTPProxy(t, "user", "secret")
+
+		if err := execute(t, &rcon.HTTPProxyDialer{Address: address, Username: "user", Password: "secret"}); err != nil {
+			t.Fatalf("got err %q, want %v", err, nil)
+		}
+
+		err := execute(t, &rcon.HTTPProxyDialer{Address: address, Username: "user", Password: "wrong"})
+		if !errors.Is(err, rcon.ErrProxyAuthFailed) {
+			t.Errorf("got err %q, want %q", err, rcon.ErrProxyAuthFailed)
+		}
+	})
+
+	t.Run("unsupported scheme", func(t *testing.T) {
+		if _, err := rcon.ProxyDialer(&url.URL{Scheme: "ftp", Host: "127.0.0.1:21"}, nil); !errors.Is(err, rcon.ErrProxyScheme) {
+			t.Errorf("got err %q, want %q", err, rcon.ErrProxyScheme)
+		}
+	})
+}
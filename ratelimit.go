@@ -0,0 +1,170 @@
+package rcon
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+)
+
+// ErrRateLimited is returned when the command exceeds the rate limit and
+// RateLimitFailFast mode is used.
+var ErrRateLimited = errors.New("rate limit exceeded")
+
+// RateLimitMode defines what happens with the command exceeding the rate
+// limit.
+type RateLimitMode int
+
+const (
+	// RateLimitWait delays the command until it is allowed by the rate limit
+	// or the context is done.
+	RateLimitWait RateLimitMode = iota
+
+	// RateLimitFailFast fails the command with ErrRateLimited right away.
+	RateLimitFailFast
+)
+
+// RateLimiter limits the rate of commands with token buckets. A
+// RateLimiter is shared by all connections created with the same
+// SetRateLimiter option, so the limits apply to the server as a whole
+// rather than to each connection, e.g. to all Pool connections and to the
+// connections re-dialed by Client.
+type RateLimiter struct {
+	mode   RateLimitMode
+	global *tokenBucket
+
+	mu      sync.Mutex
+	buckets map[string]*tokenBucket
+}
+
+// NewRateLimiter creates RateLimiter which allows rate commands per second
+// with burst commands sent at once. Commands are not limited by it if rate
+// is zero, only by the limits set with SetCommandLimit. Commands exceeding
+// the limit wait or fail depending on mode.
+func NewRateLimiter(rate float64, burst int, mode RateLimitMode) *RateLimiter {
+	limiter := RateLimiter{mode: mode, buckets: make(map[string]*tokenBucket)}
+
+	if rate > 0 {
+		limiter.global = newTokenBucket(rate, burst)
+	}
+
+	return &limiter
+}
+
+// SetCommandLimit sets the rate limit of commands starting with prefix.
+// Prefixes are case-insensitive, the longest matching prefix wins. Commands
+// matching the prefix are not limited by the rate of NewRateLimiter.
+func (l *RateLimiter) SetCommandLimit(prefix string, rate float64, burst int) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.buckets[strings.ToLower(prefix)] = newTokenBucket(rate, burst)
+}
+
+// bucket returns the bucket with the longest prefix matching the command or
+// the global bucket.
+func (l *RateLimiter) bucket(command string) *tokenBucket {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	bucket := l.global
+	command = strings.ToLower(command)
+	longest := -1
+
+	for prefix, b := range l.buckets {
+		if strings.HasPrefix(command, prefix) && len(prefix) > longest {
+			bucket, longest = b, len(prefix)
+		}
+	}
+
+	return bucket
+}
+
+// wait takes a token for the command. Nil RateLimiter allows all commands.
+func (l *RateLimiter) wait(ctx context.Context, command string) error {
+	if l == nil {
+		return nil
+	}
+
+	bucket := l.bucket(command)
+	if bucket == nil {
+		return nil
+	}
+
+	delay, ok := bucket.take(time.Now(), l.mode == RateLimitWait)
+	if !ok {
+		return ErrRateLimited
+	}
+
+	if delay == 0 {
+		return nil
+	}
+
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		bucket.restore()
+
+		return fmt.Errorf("rcon: %w", ctx.Err())
+	}
+}
+
+// tokenBucket is a token bucket refilled with rate tokens per second up to
+// burst tokens.
+type tokenBucket struct {
+	mu     sync.Mutex
+	rate   float64
+	burst  float64
+	tokens float64
+	last   time.Time
+}
+
+// newTokenBucket creates a full tokenBucket.
+func newTokenBucket(rate float64, burst int) *tokenBucket {
+	if burst < 1 {
+		burst = 1
+	}
+
+	return &tokenBucket{rate: rate, burst: float64(burst), tokens: float64(burst), last: time.Now()}
+}
+
+// take takes a token and returns the delay until it is available. If reserve
+// is false and no token is available right now, it returns false.
+func (b *tokenBucket) take(now time.Time, reserve bool) (time.Duration, bool) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if elapsed := now.Sub(b.last); elapsed > 0 {
+		b.tokens = min(b.burst, b.tokens+elapsed.Seconds()*b.rate)
+		b.last = now
+	}
+
+	if b.tokens >= 1 {
+		b.tokens--
+
+		return 0, true
+	}
+
+	if !reserve || b.rate <= 0 {
+		return 0, false
+	}
+
+	// The token is borrowed from the future, next commands wait longer.
+	b.tokens--
+
+	return time.Duration(-b.tokens / b.rate * float64(time.Second)), true
+}
+
+// restore returns the token taken by the cancelled command.
+func (b *tokenBucket) restore() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.tokens = min(b.burst, b.tokens+1)
+}
@@ -0,0 +1,186 @@
+package rcon_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/gorcon/rcon"
+	"github.com/gorcon/rcon/rcontest"
+)
+
+func TestSetRateLimiter(t *testing.T) {
+	server := rcontest.NewServer(
+		rcontest.SetSettings(rcontest.Settings{Password: "password"}),
+		rcontest.SetCommandHandler(commandHandler),
+	)
+	defer server.Close()
+
+	dial := func(t *testing.T, options ...rcon.Option) *rcon.Conn {
+		t.Helper()
+
+		conn, err := rcon.Dial(server.Addr(), "password", options...)
+		if err != nil {
+			t.Fatalf("got err %q, want %v", err, nil)
+		}
+
+		return conn
+	}
+
+	t.Run("fail fast", func(t *testing.T) {
+		conn := dial(t, rcon.SetRateLimiter(rcon.NewRateLimiter(1, 2, rcon.RateLimitFailFast)))
+		defer conn.Close()
+
+		for range 2 {
+			if _, err := conn.Execute("help"); err != nil {
+				t.Fatalf("got err %q, want %v", err, nil)
+			}
+		}
+
+		if _, err := conn.Execute("help"); !errors.Is(err, rcon.ErrRateLimited) {
+			t.Errorf("got err %q, want %q", err, rcon.ErrRateLimited)
+		}
+	})
+
+	t.Run("wait", func(t *testing.T) {
+		conn := dial(t, rcon.SetRateLimiter(rcon.NewRateLimiter(20, 1, rcon.RateLimitWait)))
+		defer conn.Close()
+
+		start := time.Now()
+
+		for range 3 {
+			if _, err := conn.Execute("help"); err != nil {
+				t.Fatalf("got err %q, want %v", err, nil)
+			}
+		}
+
+		if elapsed := time.Since(start); elapsed < 90*time.Millisecond {
+			t.Errorf("got elapsed %s, want at least %s", elapsed, 100*time.Millisecond)
+		}
+	})
+
+	t.Run("command prefix", func(t *testing.T) {
+		limiter := rcon.NewRateLimiter(1, 1, rcon.RateLimitFailFast)
+		limiter.SetCommandLimit("say", 1000, 10)
+
+		conn := dial(t, rcon.SetRateLimiter(limiter))
+		defer conn.Close()
+
+		if _, err := conn.Execute("help"); err != nil {
+			t.Fatalf("got err %q, want %v", err, nil)
+		}
+
+		if _, err := conn.Execute("help"); !errors.Is(err, rcon.ErrRateLimited) {
+			t.Errorf("got err %q, want %q", err, rcon.ErrRateLimited)
+		}
+
+		for range 3 {
+			if _, err := conn.Execute("SAY hello"); err != nil {
+				t.Errorf("got err %q, want %v", err, nil)
+			}
+		}
+	})
+
+	t.Run("context done while waiting", func(t *testing.T) {
+		conn := dial(t, rcon.SetRateLimiter(rcon.NewRateLimiter(1, 1, rcon.RateLimitWait)))
+		defer conn.Close()
+
+		if _, err := conn.Execute("help"); err != nil {
+			t.Fatalf("got err %q, want %v", err, nil)
+		}
+
+		ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+		defer cancel()
+
+		if _, err := conn.ExecuteContext(ctx, "help"); !errors.Is(err, context.DeadlineExceeded) {
+			t.Errorf("got err %q, want %q", err, context.DeadlineExceeded)
+		}
+	})
+
+	t.Run("shared by connections", func(t *testing.T) {
+		limiter := rcon.NewRateLimiter(1, 2, rcon.RateLimitFailFast)
+
+		first := dial(t, rcon.SetRateLimiter(limiter))
+		defer first.Close()
+
+		second := dial(t, rcon.SetRateLimiter(limiter))
+		defer second.Close()
+
+		for _, conn := range []*rcon.Conn{first, second} {
+			if _, err := conn.Execute("help"); err != nil {
+				t.Fatalf("got err %q, want %v", err, nil)
+			}
+		}
+
+		for _, conn := range []*rcon.Conn{first, second} {
+			if _, err := conn.Execute("help"); !errors.Is(err, rcon.ErrRateLimited) {
+				t.Errorf("got err %q, want %q", err, rcon.ErrRateLimited)
+			}
+		}
+	})
+
+	t.Run("shared by pool connections", func(t *testing.T) {
+		pool := rcon.NewPool(
+			rcon.SetPoolConnOptions(rcon.SetRateLimiter(rcon.NewRateLimiter(1, 2, rcon.RateLimitFailFast))),
+		)
+		defer pool.Close()
+
+		conns := make([]*rcon.PooledConn, 3)
+
+		for i := range conns {
+			pc, err := pool.Get(context.Background(), server.Addr(), "password")
+			if err != nil {
+				t.Fatalf("got err %q, want %v", err, nil)
+			}
+			defer pc.Release()
+
+			conns[i] = pc
+		}
+
+		for i, pc := range conns {
+			_, err := pc.Execute("help")
+			if i < 2 && err != nil {
+				t.Fatalf("got err %q, want %v", err, nil)
+			}
+
+			if i == 2 && !errors.Is(err, rcon.ErrRateLimited) {
+				t.Errorf("got err %q, want %q", err, rcon.ErrRateLimited)
+			}
+		}
+	})
+
+	t.Run("not refilled by reconnect", func(t *testing.T) {
+		server := rcontest.NewServer(
+			rcontest.SetSettings(rcontest.Settings{Password: "password"}),
+			rcontest.SetCommandHandler(func(c *rcontest.Context) {
+				if c.Request().Body() == "crash" {
+					c.Conn().Close()
+
+					return
+				}
+
+				commandHandler(c)
+			}),
+		)
+		defer server.Close()
+
+		client := rcon.NewClient(server.Addr(), "password",
+			rcon.SetConnOptions(rcon.SetRateLimiter(rcon.NewRateLimiter(0.01, 2, rcon.RateLimitFailFast))),
+			rcon.SetReconnectBackoff(time.Millisecond, time.Millisecond),
+		)
+		defer client.Close()
+
+		if _, err := client.Execute("crash"); err == nil {
+			t.Fatal("got nil err, want connection error")
+		}
+
+		if _, err := client.Execute("help"); err != nil {
+			t.Fatalf("got err %q, want %v", err, nil)
+		}
+
+		if _, err := client.Execute("help"); !errors.Is(err, rcon.ErrRateLimited) {
+			t.Errorf("got err %q, want %q", err, rcon.ErrRateLimited)
+		}
+	})
+}
@@ -3,10 +3,16 @@
 package rcon
 
 import (
+	"context"
 	"encoding/binary"
 	"errors"
 	"fmt"
+	"io"
+	"log/slog"
 	"net"
+	"os"
+	"sync"
+	"sync/atomic"
 	"time"
 )
 
@@ -27,6 +33,8 @@ const (
 
 	// SERVERDATA_AUTH_ID is any positive integer, chosen by the client
 	// (will be mirrored back in the server's response).
+	//
+	// Deprecated: Conn allocates a unique packet ID for each request.
 	SERVERDATA_AUTH_ID int32 = 0
 
 	// SERVERDATA_AUTH_RESPONSE packet is a notification of the conn's current auth
@@ -50,9 +58,37 @@ const (
 
 	// SERVERDATA_EXECCOMMAND_ID is any positive integer, chosen by the client
 	// (will be mirrored back in the server's response).
+	//
+	// Deprecated: Conn allocates a unique packet ID for each request.
 	SERVERDATA_EXECCOMMAND_ID int32 = 0
 )
 
+// MultiPacketStrategy defines how Conn detects the end of a response which
+// the server has split into multiple SERVERDATA_RESPONSE_VALUE packets.
+type MultiPacketStrategy int
+
+const (
+	// MultiPacketNone reads exactly one response packet per command.
+	MultiPacketNone MultiPacketStrategy = iota
+
+	// MultiPacketMirror sends an empty SERVERDATA_RESPONSE_VALUE packet with
+	// its own packet ID right after the command. The server processes packets in order, so it mirrors
+	// the empty packet back only after the whole command response has been
+	// sent. All the packets received before the mirrored one are joined.
+	// The server must respond to SERVERDATA_RESPONSE_VALUE requests, otherwise
+	// Execute fails with the read deadline error.
+	MultiPacketMirror
+
+	// MultiPacketSentinel is like MultiPacketMirror, but the empty
+	// SERVERDATA_RESPONSE_VALUE packet is sent only after the first response
+	// packet is received. It is required by servers which can't handle more
+	// than one packet received at once, like Minecraft. Any server response
+	// to the packet ends the command response, so the server may respond to
+	// it with an error message. Commands are not pipelined with this strategy
+	// unless SetMaxInFlight option is set.
+	MultiPacketSentinel
+)
+
 var (
 	// ErrAuthNotRCON is returned when got auth response with negative size.
 	ErrAuthNotRCON = errors.New("response from not rcon server")
@@ -67,7 +103,7 @@ var (
 	ErrAuthFailed = errors.New("authentication failed")
 
 	// ErrInvalidPacketID is returned when the package id from server response
-	// was not mirrored back from request.
+	// was not mirrored back from request and was never allocated by Conn.
 	ErrInvalidPacketID = errors.New("response for another request")
 
 	// ErrInvalidPacketPadding is returned when the bytes after type field from
@@ -90,25 +126,108 @@ var (
 	ErrMultiErrorOccurred = errors.New("an error occurred while handling another error")
 )
 
+// Response is a command response returned by Conn.Do.
+type Response struct {
+	// ID is the packet ID the command was sent with. It can be used to
+	// correlate requests and responses in logs.
+	ID int32
+
+	// Body is the response body joined from all response packets.
+	Body string
+}
+
 // Conn is source RCON generic stream-oriented network connection.
+// Conn is safe for concurrent use by multiple goroutines. Commands are
+// pipelined on the same connection and responses are matched to them by
+// packet ID in a background reader.
 type Conn struct {
 	conn     net.Conn
 	settings Settings
+
+	// address is the server address reported to metrics.
+	address string
+
+	// writeMu serializes writes of request packets.
+	writeMu sync.Mutex
+
+	// inflight limits the number of requests awaiting response. It is nil
+	// when the number is unlimited.
+	inflight chan struct{}
+
+	// done is closed when the background reader exits.
+	done chan struct{}
+
+	// heartbeatDone is closed when the heartbeat goroutine exits. It is nil
+	// when heartbeat is disabled.
+	heartbeatDone chan struct{}
+
+	// lastRead is the time of the last packet received, in Unix nanoseconds.
+	lastRead atomic.Int64
+
+	// mu guards the fields below and serializes conn deadline updates with
+	// context cancellation.
+	mu sync.Mutex
+
+	// lastID is the last packet ID allocated by nextID.
+	lastID int32
+
+	// pending holds requests awaiting response by both their packet ID and
+	// terminator packet ID.
+	pending map[int32]*request
+
+	// subscriptions receive unsolicited packets.
+	subscriptions []*Subscription
+
+	// err is the error which stopped the background reader.
+	err error
+
+	// closed is set when Close is called.
+	closed bool
 }
 
 // open creates a new Conn from an existing net.Conn and authenticates it.
-func open(conn net.Conn, password string, settings Settings) (*Conn, error) {
-	client := Conn{conn: conn, settings: settings}
+func open(ctx context.Context, conn net.Conn, address string, password string, settings Settings) (*Conn, error) {
+	if settings.logger != nil {
+		settings.logger = settings.logger.With(slog.String("remote_addr", conn.RemoteAddr().String()))
+	}
+
+	client := Conn{
+		conn:     conn,
+		settings: settings,
+		address:  address,
+		pending:  make(map[int32]*request),
+	}
 
-	if err := client.auth(password); err != nil {
+	if err := client.authenticate(ctx, password); err != nil {
 		// Failed to auth conn with the server.
-		if err2 := client.Close(); err2 != nil {
+		if err2 := client.Close(); err2 != nil && !errors.Is(err2, net.ErrClosed) {
 			return &client, fmt.Errorf("%w: %s. Previous error: %s", ErrMultiErrorOccurred, err2.Error(), err.Error())
 		}
 
 		return &client, fmt.Errorf("rcon: %w", err)
 	}
 
+	if settings.maxInFlight == 0 && settings.multiPacket == MultiPacketSentinel {
+		settings.maxInFlight = 1
+	}
+
+	if settings.maxInFlight > 0 {
+		client.inflight = make(chan struct{}, settings.maxInFlight)
+	}
+
+	settings.debug(ctx, "rcon: authenticated", slog.String("dialect", settings.dialect.Name()))
+
+	client.done = make(chan struct{})
+	client.lastRead.Store(time.Now().UnixNano())
+
+	go client.readLoop()
+
+	if settings.heartbeatInterval > 0 {
+		client.heartbeatDone = make(chan struct{})
+
+		go client.heartbeat()
+	}
+
 	return &client, nil
 }
 
@@ -119,53 +238,133 @@ func Open(conn net.Conn, password string, options ...Option) (*Conn, error) {
 		option(&settings)
 	}
 
-	return open(conn, password, settings)
+	return open(context.Background(), conn, conn.RemoteAddr().String(), password, settings)
 }
 
 // Dial creates a new authorized Conn tcp dialer connection.
 func Dial(address string, password string, options ...Option) (*Conn, error) {
+	return DialContext(context.Background(), address, password, options...)
+}
+
+// DialContext creates a new authorized Conn tcp dialer connection using the
+// provided context. The context only affects connecting and authentication,
+// once Conn is returned, cancellation of the context has no effect on it.
+// If the context is done before Conn is authenticated, the connection is
+// closed and the context error is returned.
+func DialContext(ctx context.Context, address string, password string, options ...Option) (*Conn, error) {
 	settings := DefaultSettings
 
 	for _, option := range options {
 		option(&settings)
 	}
 
-	conn, err := net.DialTimeout("tcp", address, settings.dialTimeout)
+	ctx, span := settings.tracer.Start(ctx, SpanDial, Attribute{Key: AttributeServerAddress, Value: address})
+
+	if err := settings.breaker.allow(address); err != nil {
+		endSpan(span, err)
+
+		return nil, err
+	}
+
+	conn, err := dialContext(ctx, address, password, settings)
+	settings.breaker.done(address, err)
+	endSpan(span, err)
+
+	return conn, err
+}
+
+// dialContext connects to the server and authenticates.
+func dialContext(ctx context.Context, address string, password string, settings Settings) (*Conn, error) {
+	// The dial timeout covers both TCP dial and TLS handshake.
+	dialCtx := ctx
+
+	if settings.dialTimeout != 0 {
+		var cancel context.CancelFunc
+
+		dialCtx, cancel = context.WithTimeout(ctx, settings.dialTimeout)
+		defer cancel()
+	}
+
+	start := time.Now()
+	conn, err := dial(dialCtx, address, settings)
+
+	if err == nil && settings.tlsConfig != nil {
+		conn, err = handshake(dialCtx, conn, address, settings)
+	}
+
+	settings.metrics.ObserveDial(address, time.Since(start), err)
+
 	if err != nil {
+		settings.debug(ctx, "rcon: dial failed", slog.String("address", address), errorAttr(err))
+
 		// Failed to open TCP connection to the server.
 		return nil, fmt.Errorf("rcon: %w", err)
 	}
 
-	return open(conn, password, settings)
+	settings.debug(ctx, "rcon: dialed", slog.String("address", address),
+		slog.String("local_addr", conn.LocalAddr().String()))
+
+	return open(ctx, conn, address, password, settings)
 }
 
 // Execute sends command type and it string to execute to the remote server,
-// creating a packet with a SERVERDATA_EXECCOMMAND_ID for the server to mirror,
+// creating a packet with a unique packet ID for the server to mirror,
 // and compiling its payload bytes in the appropriate order. The response body
 // is decompiled from bytes into a string for return.
 func (c *Conn) Execute(command string) (string, error) {
+	return c.ExecuteContext(context.Background(), command)
+}
+
+// ExecuteContext is like Execute but stops waiting for the response when ctx
+// is done and returns the context error. Conn stays usable, the late response
+// is discarded as stale when it arrives.
+func (c *Conn) ExecuteContext(ctx context.Context, command string) (string, error) {
+	response, err := c.Do(ctx, command)
+
+	return response.Body, err
+}
+
+// Do is like ExecuteContext but returns the Response which holds the packet
+// ID the command was sent with. Response is never nil, even on error.
+func (c *Conn) Do(ctx context.Context, command string) (*Response, error) {
+	ctx, span := c.settings.tracer.Start(ctx, SpanExecute,
+		Attribute{Key: AttributeServerAddress, Value: c.address},
+		Attribute{Key: AttributeCommand, Value: commandName(command)},
+	)
+
+	response, err := c.do(ctx, command)
+
+	span.SetAttributes(
+		Attribute{Key: AttributePacketID, Value: response.ID},
+		Attribute{Key: AttributeResponseSize, Value: len(response.Body)},
+	)
+	endSpan(span, err)
+
+	return response, err
+}
+
+// do validates the command and executes it.
+func (c *Conn) do(ctx context.Context, command string) (*Response, error) {
 	if command == "" {
-		return "", ErrCommandEmpty
+		return &Response{}, ErrCommandEmpty
 	}
 
-	if c.settings.maxCommandLen > 0 && len(command) > c.settings.maxCommandLen {
-		return "", ErrCommandTooLong
-	}
+	body := string(c.settings.encoding.Encode(command))
 
-	if err := c.write(SERVERDATA_EXECCOMMAND, SERVERDATA_EXECCOMMAND_ID, command); err != nil {
-		return "", err
+	if c.settings.maxCommandLen > 0 && len(body) > c.settings.maxCommandLen {
+		return &Response{}, ErrCommandTooLong
 	}
 
-	response, err := c.read()
-	if err != nil {
-		return response.Body(), err
+	if err := c.settings.breaker.allow(c.address); err != nil {
+		return &Response{}, err
 	}
 
-	if response.ID != SERVERDATA_EXECCOMMAND_ID {
-		return response.Body(), ErrInvalidPacketID
-	}
+	start := time.Now()
+	response, err := c.executeWithRetry(ctx, command, body)
+	c.settings.metrics.ObserveExecute(c.address, time.Since(start), err)
+	c.settings.breaker.done(c.address, err)
 
-	return response.Body(), nil
+	return response, err
 }
 
 // LocalAddr returns the local network address.
@@ -178,22 +377,183 @@ func (c *Conn) RemoteAddr() net.Addr {
 	return c.conn.RemoteAddr()
 }
 
-// Close closes the connection.
+// Close closes the connection. Commands awaiting response are failed.
 func (c *Conn) Close() error {
-	return c.conn.Close()
+	c.mu.Lock()
+	c.closed = true
+	c.mu.Unlock()
+
+	err := c.conn.Close()
+
+	if c.done != nil {
+		<-c.done
+	}
+
+	if c.heartbeatDone != nil {
+		<-c.heartbeatDone
+	}
+
+	return err
+}
+
+// execute writes request packet to the server and waits for the response
+// reassembled with strategy.
+func (c *Conn) execute(
+	ctx context.Context, packetType int32, body string, strategy MultiPacketStrategy,
+) (*Response, error) {
+	if err := ctx.Err(); err != nil {
+		return &Response{}, fmt.Errorf("rcon: %w", err)
+	}
+
+	if c.inflight != nil {
+		select {
+		case c.inflight <- struct{}{}:
+			defer func() { <-c.inflight }()
+		case <-ctx.Done():
+			return &Response{}, fmt.Errorf("rcon: %w", ctx.Err())
+		}
+	}
+
+	req, err := c.register(strategy)
+	if err != nil {
+		return &Response{}, err
+	}
+
+	response := &Response{ID: req.id}
+
+	err = c.send(ctx, func() (int64, error) {
+		n, err := c.write(ctx, packetType, req.id, body)
+		if err == nil && strategy == MultiPacketMirror {
+			var m int64
+
+			m, err = c.write(ctx, SERVERDATA_RESPONSE_VALUE, req.terminatorID, "")
+			n += m
+		}
+
+		return n, err
+	})
+	if err != nil {
+		c.unregister(req)
+
+		return response, err
+	}
+
+	if strategy == MultiPacketSentinel {
+		if err := c.sendSentinel(ctx, req); err != nil {
+			c.unregister(req)
+
+			return response, err
+		}
+	}
+
+	select {
+	case <-req.done:
+		response.Body = c.settings.encoding.Decode(req.body)
+
+		return response, req.err
+	case <-ctx.Done():
+		c.unregister(req)
+
+		return response, fmt.Errorf("rcon: %w", ctx.Err())
+	}
+}
+
+// sendSentinel waits for the first response packet and sends the empty
+// SERVERDATA_RESPONSE_VALUE packet for MultiPacketSentinel strategy.
+func (c *Conn) sendSentinel(ctx context.Context, req *request) error {
+	select {
+	case <-req.first:
+		return c.send(ctx, func() (int64, error) {
+			return c.write(ctx, SERVERDATA_RESPONSE_VALUE, req.terminatorID, "")
+		})
+	case <-req.done:
+		// Request failed before the first packet.
+		return nil
+	case <-ctx.Done():
+		return fmt.Errorf("rcon: %w", ctx.Err())
+	}
+}
+
+// send writes request packets to the server with write, which returns the
+// number of written bytes. When ctx is done or the deadline is exceeded
+// before anything is written, for example while waiting for another
+// goroutine to finish writing, the conn stays usable. Otherwise the conn is
+// broken after write failure, so it is closed.
+func (c *Conn) send(ctx context.Context, write func() (int64, error)) error {
+	c.writeMu.Lock()
+	defer c.writeMu.Unlock()
+
+	if n, err := write(); err != nil {
+		if n == 0 && (ctx.Err() != nil || errors.Is(err, os.ErrDeadlineExceeded)) {
+			return contextError(ctx, err)
+		}
+
+		err = c.interrupted(ctx, err)
+		c.fail(err)
+
+		return err
+	}
+
+	c.mu.Lock()
+	c.updateReadDeadline()
+	c.mu.Unlock()
+
+	return nil
+}
+
+// authenticate authenticates Conn and reports it to the tracer, metrics and
+// logger.
+func (c *Conn) authenticate(ctx context.Context, password string) error {
+	ctx, span := c.settings.tracer.Start(ctx, SpanAuth, Attribute{Key: AttributeServerAddress, Value: c.address})
+
+	start := time.Now()
+	err := c.authContext(ctx, password)
+	c.settings.metrics.ObserveAuth(c.address, time.Since(start), err)
+
+	// The auth request has the last allocated packet ID.
+	span.SetAttributes(Attribute{Key: AttributePacketID, Value: c.lastID})
+	endSpan(span, err)
+
+	if err != nil {
+		c.settings.debug(ctx, "rcon: auth failed", errorAttr(err))
+	}
+
+	return err
+}
+
+// authContext authenticates the client like auth, but aborts the handshake
+// when ctx is done.
+func (c *Conn) authContext(ctx context.Context, password string) error {
+	stop := c.watch(ctx)
+	err := c.auth(ctx, password)
+	stop()
+
+	if err != nil {
+		return c.interrupted(ctx, err)
+	}
+
+	// Deadlines of the handshake must not outlive it. Read deadline is
+	// managed by the background reader from now on and write deadline is set
+	// for each command.
+	if err := c.conn.SetDeadline(time.Time{}); err != nil {
+		return fmt.Errorf("rcon: %w", err)
+	}
+
+	return nil
 }
 
 // auth sends SERVERDATA_AUTH request to the remote server and
 // authenticates client for the next requests.
-func (c *Conn) auth(password string) error {
-	if err := c.write(SERVERDATA_AUTH, SERVERDATA_AUTH_ID, password); err != nil {
+func (c *Conn) auth(ctx context.Context, password string) error {
+	id := c.nextID()
+
+	// The password is encoded like command bodies.
+	if _, err := c.write(ctx, SERVERDATA_AUTH, id, string(c.settings.encoding.Encode(password))); err != nil {
 		return err
 	}
 
-	if c.settings.deadline != 0 {
-		if err := c.conn.SetReadDeadline(time.Now().Add(c.settings.deadline)); err != nil {
-			return fmt.Errorf("rcon: %w", err)
-		}
+	if err := c.setDeadline(ctx, c.conn.SetReadDeadline); err != nil {
+		return err
 	}
 
 	response, err := c.readHeader()
@@ -206,26 +566,34 @@ func (c *Conn) auth(password string) error {
 		return ErrAuthNotRCON
 	}
 
-	// When the server receives an auth request, it will respond with an empty
-	// SERVERDATA_RESPONSE_VALUE, followed immediately by a SERVERDATA_AUTH_RESPONSE
-	// indicating whether authentication succeeded or failed.
-	// Some servers doesn't send an empty SERVERDATA_RESPONSE_VALUE packet, so we
-	// do this case optional.
-	if response.Type == SERVERDATA_RESPONSE_VALUE {
-		// Discard empty SERVERDATA_RESPONSE_VALUE from authentication response.
-		_, _ = c.conn.Read(make([]byte, size))
+	// Skip packets preceding SERVERDATA_AUTH_RESPONSE, e.g. an empty
+	// SERVERDATA_RESPONSE_VALUE sent by Source servers.
+	for c.settings.dialect.SkipAuthPacket(&response) {
+		c.settings.debug(ctx, "rcon: skip auth packet", packetAttr(&response))
+
+		if _, err := io.CopyN(io.Discard, c.conn, int64(size)); err != nil {
+			return fmt.Errorf("rcon: %w", err)
+		}
+
+		c.settings.metrics.ObserveRead(c.address, int(response.Size)+4, nil)
 
 		if response, err = c.readHeader(); err != nil {
 			return err
 		}
+
+		if size = response.Size - PacketHeaderSize; size < 0 {
+			return ErrAuthNotRCON
+		}
 	}
 
 	// We must to read response body.
-	buffer := make([]byte, size)
-	if _, err := c.conn.Read(buffer); err != nil {
+	if _, err := io.ReadFull(c.conn, make([]byte, size)); err != nil {
 		return fmt.Errorf("rcon: %w", err)
 	}
 
+	c.settings.debug(ctx, "rcon: read auth response", packetAttr(&response))
+	c.settings.metrics.ObserveRead(c.address, int(response.Size)+4, nil)
+
 	if response.Type != SERVERDATA_AUTH_RESPONSE {
 		return ErrInvalidAuthResponse
 	}
@@ -234,59 +602,34 @@ func (c *Conn) auth(password string) error {
 		return ErrAuthFailed
 	}
 
-	if response.ID != SERVERDATA_AUTH_ID {
+	if response.ID != id {
 		return ErrInvalidPacketID
 	}
 
 	return nil
 }
 
-// write creates packet and writes it to established tcp conn.
-func (c *Conn) write(packetType int32, packetID int32, command string) error {
-	if c.settings.deadline != 0 {
-		if err := c.conn.SetWriteDeadline(time.Now().Add(c.settings.deadline)); err != nil {
-			return fmt.Errorf("rcon: %w", err)
-		}
+// write creates packet and writes it to established tcp conn. It returns
+// the number of written bytes.
+func (c *Conn) write(ctx context.Context, packetType int32, packetID int32, command string) (int64, error) {
+	if err := c.setDeadline(ctx, c.conn.SetWriteDeadline); err != nil {
+		return 0, err
 	}
 
 	packet := NewPacket(packetType, packetID, command)
-	_, err := packet.WriteTo(c.conn)
 
-	return err
-}
+	n, err := packet.WriteTo(c.conn)
+	c.settings.metrics.ObserveWrite(c.address, int(n), err)
 
-// read reads structured binary data from c.conn into packet.
-func (c *Conn) read() (*Packet, error) {
-	if c.settings.deadline != 0 {
-		if err := c.conn.SetReadDeadline(time.Now().Add(c.settings.deadline)); err != nil {
-			return nil, fmt.Errorf("rcon: %w", err)
-		}
-	}
+	if err != nil {
+		c.settings.debug(ctx, "rcon: write failed", packetAttr(packet), errorAttr(err))
 
-	packet := &Packet{}
-	if _, err := packet.ReadFrom(c.conn); err != nil {
-		return packet, err
+		return n, err
 	}
 
-	// Workaround for Rust server.
-	// Rust rcon server responses packet with a type of 4 and the next packet
-	// is valid. It is undocumented, so skip packet and read next.
-	if packet.Type == 4 {
-		if _, err := packet.ReadFrom(c.conn); err != nil {
-			return packet, err
-		}
-
-		// One more workaround for Rust server.
-		// When sent command "Say" there is no response data from server with
-		// packet.ID = SERVERDATA_EXECCOMMAND_ID, only previous console message
-		// that command was received with packet.ID = -1, therefore, forcibly
-		// set packet.ID to SERVERDATA_EXECCOMMAND_ID.
-		if packet.ID == -1 {
-			packet.ID = SERVERDATA_EXECCOMMAND_ID
-		}
-	}
+	c.settings.debug(ctx, "rcon: write packet", packetAttr(packet))
 
-	return packet, nil
+	return n, nil
 }
 
 // readHeader reads structured binary data without body from c.conn into packet.
@@ -306,3 +649,88 @@ func (c *Conn) readHeader() (Packet, error) {
 
 	return packet, nil
 }
+
+// setDeadline sets the deadline for the next I/O operation with set, which is
+// the earliest of the configured deadline and the ctx deadline. The deadline
+// left by the previous operation is cleared when neither of them is present.
+func (c *Conn) setDeadline(ctx context.Context, set func(t time.Time) error) error {
+	var deadline time.Time
+
+	if c.settings.deadline != 0 {
+		deadline = time.Now().Add(c.settings.deadline)
+	}
+
+	if d, ok := ctx.Deadline(); ok && (deadline.IsZero() || d.Before(deadline)) {
+		deadline = d
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	// Don't override the deadline which was set by watch.
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	if err := set(deadline); err != nil && !deadline.IsZero() {
+		// Failure to clear the deadline is reported by the I/O operation.
+		return fmt.Errorf("rcon: %w", err)
+	}
+
+	return nil
+}
+
+// watch interrupts blocking conn operations by setting a deadline in the past
+// when ctx is done. The returned function stops watching and reports whether
+// the deadline was set.
+func (c *Conn) watch(ctx context.Context) func() bool {
+	if ctx.Done() == nil {
+		return func() bool { return false }
+	}
+
+	done := make(chan struct{})
+	fired := make(chan bool, 1)
+
+	go func() {
+		select {
+		case <-ctx.Done():
+			c.mu.Lock()
+			_ = c.conn.SetDeadline(time.Unix(1, 0))
+			c.mu.Unlock()
+
+			fired <- true
+		case <-done:
+			fired <- false
+		}
+	}()
+
+	return func() bool {
+		close(done)
+
+		return <-fired
+	}
+}
+
+// interrupted returns the error like contextError and closes the conn,
+// because it may contain partially read or written packets.
+func (c *Conn) interrupted(ctx context.Context, err error) error {
+	_ = c.conn.Close()
+
+	return contextError(ctx, err)
+}
+
+// contextError returns the ctx error wrapped in rcon error chain instead of
+// err when the operation was aborted because ctx is done.
+func contextError(ctx context.Context, err error) error {
+	ctxErr := ctx.Err()
+	if ctxErr == nil {
+		d, ok := ctx.Deadline()
+		if !ok || !errors.Is(err, os.ErrDeadlineExceeded) || time.Now().Before(d) {
+			return err
+		}
+
+		ctxErr = context.DeadlineExceeded
+	}
+
+	return fmt.Errorf("rcon: %w", ctxErr)
+}
@@ -2,12 +2,15 @@ package rcon_test
 
 import (
 	"bytes"
+	"context"
 	"encoding/binary"
 	"errors"
 	"fmt"
 	"io"
+	"net"
 	"os"
 	"strings"
+	"sync"
 	"testing"
 	"time"
 
@@ -63,6 +66,13 @@ func commandHandler(c *rcontest.Context) {
 		rcon.NewPacket(4, c.Request().ID, "").WriteTo(c.Conn())
 
 		rcon.NewPacket(rcon.SERVERDATA_RESPONSE_VALUE, -1, c.Request().Body()).WriteTo(c.Conn())
+	case "long":
+		c.WriteResponse(strings.Repeat("a", 5000) + strings.Repeat("b", 5000))
+	case "stale":
+		// Write late response to the previous request first.
+		rcon.NewPacket(rcon.SERVERDATA_RESPONSE_VALUE, c.Request().ID-1, "late").WriteTo(c.Conn())
+
+		rcon.NewPacket(rcon.SERVERDATA_RESPONSE_VALUE, c.Request().ID, "actual").WriteTo(c.Conn())
 	case "padding":
 		writeWithInvalidPadding(c.Conn(), rcon.NewPacket(rcon.SERVERDATA_RESPONSE_VALUE, c.Request().ID, ""))
 	case "another":
@@ -154,6 +164,391 @@ func TestDial(t *testing.T) {
 	})
 }
 
+func TestDialContext(t *testing.T) {
+	server := rcontest.NewServer(rcontest.SetSettings(rcontest.Settings{Password: "password", AuthResponseDelay: time.Second}))
+	defer server.Close()
+
+	t.Run("context deadline exceeded", func(t *testing.T) {
+		ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+		defer cancel()
+
+		_, err := rcon.DialContext(ctx, server.Addr(), "password")
+		if !errors.Is(err, context.DeadlineExceeded) {
+			t.Errorf("got err %q, want %q", err, context.DeadlineExceeded)
+		}
+	})
+
+	t.Run("context canceled", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		time.AfterFunc(100*time.Millisecond, cancel)
+
+		_, err := rcon.DialContext(ctx, server.Addr(), "password")
+		if !errors.Is(err, context.Canceled) {
+			t.Errorf("got err %q, want %q", err, context.Canceled)
+		}
+	})
+
+	t.Run("auth success", func(t *testing.T) {
+		ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+		defer cancel()
+
+		conn, err := rcon.DialContext(ctx, server.Addr(), "password")
+		if err != nil {
+			t.Fatalf("got err %q, want %v", err, nil)
+		}
+
+		conn.Close()
+	})
+
+	t.Run("deadline cleared after auth", func(t *testing.T) {
+		server := rcontest.NewServer(
+			rcontest.SetSettings(rcontest.Settings{Password: "password"}),
+			rcontest.SetCommandHandler(commandHandler),
+		)
+		defer server.Close()
+
+		ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+		defer cancel()
+
+		conn, err := rcon.DialContext(ctx, server.Addr(), "password", rcon.SetDeadline(0))
+		if err != nil {
+			t.Fatalf("got err %q, want %v", err, nil)
+		}
+		defer conn.Close()
+
+		// Deadline of the dial context has passed.
+		time.Sleep(200 * time.Millisecond)
+
+		result, err := conn.Execute("help")
+		if err != nil {
+			t.Fatalf("got err %q, want %v", err, nil)
+		}
+
+		if resultWant := "lorem ipsum dolor sit amet"; result != resultWant {
+			t.Fatalf("got result %q, want %q", result, resultWant)
+		}
+	})
+}
+
+func TestConn_ExecuteContext(t *testing.T) {
+	server := rcontest.NewServer(
+		rcontest.SetSettings(rcontest.Settings{Password: "password", CommandResponseDelay: 500 * time.Millisecond}),
+		rcontest.SetCommandHandler(commandHandler),
+	)
+	defer server.Close()
+
+	t.Run("context canceled", func(t *testing.T) {
+		conn, err := rcon.Dial(server.Addr(), "password")
+		if err != nil {
+			t.Fatalf("got err %q, want %v", err, nil)
+		}
+		defer conn.Close()
+
+		ctx, cancel := context.WithCancel(context.Background())
+		time.AfterFunc(100*time.Millisecond, cancel)
+
+		result, err := conn.ExecuteContext(ctx, "help")
+		if !errors.Is(err, context.Canceled) {
+			t.Errorf("got err %q, want %q", err, context.Canceled)
+		}
+
+		if len(result) != 0 {
+			t.Fatalf("got result len %d, want %d", len(result), 0)
+		}
+
+		// Conn is usable after the interrupted command, late response is
+		// discarded as stale.
+		result, err = conn.Execute("padding")
+		if !errors.Is(err, rcon.ErrInvalidPacketPadding) {
+			t.Errorf("got err %q, want %q", err, rcon.ErrInvalidPacketPadding)
+		}
+
+		if len(result) != 2 {
+			t.Fatalf("got result len %d, want %d", len(result), 2)
+		}
+	})
+
+	t.Run("context deadline exceeded", func(t *testing.T) {
+		conn, err := rcon.Dial(server.Addr(), "password", rcon.SetDeadline(0))
+		if err != nil {
+			t.Fatalf("got err %q, want %v", err, nil)
+		}
+		defer conn.Close()
+
+		ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+		defer cancel()
+
+		_, err = conn.ExecuteContext(ctx, "help")
+		if !errors.Is(err, context.DeadlineExceeded) {
+			t.Errorf("got err %q, want %q", err, context.DeadlineExceeded)
+		}
+	})
+
+	t.Run("deadline cleared after context", func(t *testing.T) {
+		server := rcontest.NewServer(
+			rcontest.SetSettings(rcontest.Settings{Password: "password"}),
+			rcontest.SetCommandHandler(commandHandler),
+		)
+		defer server.Close()
+
+		conn, err := rcon.Dial(server.Addr(), "password", rcon.SetDeadline(0))
+		if err != nil {
+			t.Fatalf("got err %q, want %v", err, nil)
+		}
+		defer conn.Close()
+
+		ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+		defer cancel()
+
+		if _, err := conn.ExecuteContext(ctx, "help"); err != nil {
+			t.Fatalf("got err %q, want %v", err, nil)
+		}
+
+		// Write deadline of the previous command has passed.
+		time.Sleep(100 * time.Millisecond)
+
+		if _, err := conn.Execute("help"); err != nil {
+			t.Fatalf("got err %q, want %v", err, nil)
+		}
+	})
+
+	t.Run("context done before send", func(t *testing.T) {
+		conn, err := rcon.Dial(server.Addr(), "password")
+		if err != nil {
+			t.Fatalf("got err %q, want %v", err, nil)
+		}
+		defer conn.Close()
+
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+
+		if _, err = conn.ExecuteContext(ctx, "help"); !errors.Is(err, context.Canceled) {
+			t.Errorf("got err %q, want %q", err, context.Canceled)
+		}
+
+		result, err := conn.ExecuteContext(context.Background(), "help")
+		if err != nil {
+			t.Fatalf("got err %q, want %v", err, nil)
+		}
+
+		resultWant := "lorem ipsum dolor sit amet"
+		if result != resultWant {
+			t.Fatalf("got result %q, want %q", result, resultWant)
+		}
+	})
+}
+
+func TestConn_Do(t *testing.T) {
+	server := rcontest.NewServer(
+		rcontest.SetSettings(rcontest.Settings{Password: "password"}),
+		rcontest.SetCommandHandler(commandHandler),
+	)
+	defer server.Close()
+
+	t.Run("unique packet ids", func(t *testing.T) {
+		conn, err := rcon.Dial(server.Addr(), "password")
+		if err != nil {
+			t.Fatalf("got err %q, want %v", err, nil)
+		}
+		defer conn.Close()
+
+		first, err := conn.Do(context.Background(), "help")
+		if err != nil {
+			t.Fatalf("got err %q, want %v", err, nil)
+		}
+
+		second, err := conn.Do(context.Background(), "help")
+		if err != nil {
+			t.Fatalf("got err %q, want %v", err, nil)
+		}
+
+		if first.ID <= 0 || second.ID != first.ID+1 {
+			t.Errorf("got ids %d and %d, want positive increasing ids", first.ID, second.ID)
+		}
+
+		if second.Body != "lorem ipsum dolor sit amet" {
+			t.Errorf("got body %q, want %q", second.Body, "lorem ipsum dolor sit amet")
+		}
+	})
+
+	t.Run("stale packet", func(t *testing.T) {
+		var stale []string
+
+		conn, err := rcon.Dial(server.Addr(), "password", rcon.SetStaleHandler(func(packet *rcon.Packet) {
+			stale = append(stale, packet.Body())
+		}))
+		if err != nil {
+			t.Fatalf("got err %q, want %v", err, nil)
+		}
+		defer conn.Close()
+
+		response, err := conn.Do(context.Background(), "stale")
+		if err != nil {
+			t.Fatalf("got err %q, want %v", err, nil)
+		}
+
+		if response.Body != "actual" {
+			t.Errorf("got body %q, want %q", response.Body, "actual")
+		}
+
+		if len(stale) != 1 || stale[0] != "late" {
+			t.Errorf("got stale packets %q, want %q", stale, []string{"late"})
+		}
+	})
+}
+
+func TestConn_Concurrent(t *testing.T) {
+	t.Run("pipelined commands", func(t *testing.T) {
+		server := rcontest.NewServer(
+			rcontest.SetSettings(rcontest.Settings{Password: "password"}),
+			rcontest.SetCommandHandler(func(c *rcontest.Context) {
+				rcon.NewPacket(rcon.SERVERDATA_RESPONSE_VALUE, c.Request().ID, c.Request().Body()).WriteTo(c.Conn())
+			}),
+		)
+		defer server.Close()
+
+		conn, err := rcon.Dial(server.Addr(), "password", rcon.SetMultiPacket(rcon.MultiPacketMirror))
+		if err != nil {
+			t.Fatalf("got err %q, want %v", err, nil)
+		}
+		defer conn.Close()
+
+		var wg sync.WaitGroup
+
+		for i := 0; i < 50; i++ {
+			wg.Add(1)
+
+			go func(i int) {
+				defer wg.Done()
+
+				command := fmt.Sprintf("echo %d", i)
+
+				result, err := conn.Execute(command)
+				if err != nil {
+					t.Errorf("got err %q, want %v", err, nil)
+				}
+
+				if result != command {
+					t.Errorf("got result %q, want %q", result, command)
+				}
+			}(i)
+		}
+
+		wg.Wait()
+	})
+
+	t.Run("max in flight", func(t *testing.T) {
+		client, server := net.Pipe()
+		defer server.Close()
+
+		go func() {
+			request := &rcon.Packet{}
+			request.ReadFrom(server)
+			rcon.NewPacket(rcon.SERVERDATA_AUTH_RESPONSE, request.ID, "").WriteTo(server)
+		}()
+
+		conn, err := rcon.Open(client, "password", rcon.SetMaxInFlight(1))
+		if err != nil {
+			t.Fatalf("got err %q, want %v", err, nil)
+		}
+		defer conn.Close()
+
+		for i := 0; i < 2; i++ {
+			go conn.Execute("help")
+		}
+
+		first := &rcon.Packet{}
+		if _, err := first.ReadFrom(server); err != nil {
+			t.Fatalf("got err %q, want %v", err, nil)
+		}
+
+		// Second command must not be sent until the first one is answered.
+		server.SetReadDeadline(time.Now().Add(100 * time.Millisecond))
+
+		if _, err := new(rcon.Packet).ReadFrom(server); !errors.Is(err, os.ErrDeadlineExceeded) {
+			t.Fatalf("got err %q, want %q", err, os.ErrDeadlineExceeded)
+		}
+
+		server.SetReadDeadline(time.Now().Add(time.Second))
+		rcon.NewPacket(rcon.SERVERDATA_RESPONSE_VALUE, first.ID, "").WriteTo(server)
+
+		second := &rcon.Packet{}
+		if _, err := second.ReadFrom(server); err != nil {
+			t.Fatalf("got err %q, want %v", err, nil)
+		}
+
+		if second.ID <= first.ID {
+			t.Errorf("got id %d, want greater than %d", second.ID, first.ID)
+		}
+	})
+
+	t.Run("context done while waiting to write", func(t *testing.T) {
+		client, server := net.Pipe()
+		defer server.Close()
+
+		go func() {
+			request := &rcon.Packet{}
+			request.ReadFrom(server)
+			rcon.NewPacket(rcon.SERVERDATA_AUTH_RESPONSE, request.ID, "").WriteTo(server)
+		}()
+
+		conn, err := rcon.Open(client, "password", rcon.SetMultiPacket(rcon.MultiPacketNone))
+		if err != nil {
+			t.Fatalf("got err %q, want %v", err, nil)
+		}
+		defer conn.Close()
+
+		// Pipe writes block until the server reads, so the first command
+		// holds the write lock.
+		first := make(chan error, 1)
+
+		go func() {
+			_, err := conn.Execute("first")
+			first <- err
+		}()
+
+		time.Sleep(50 * time.Millisecond)
+
+		second := make(chan error, 1)
+
+		go func() {
+			ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+			defer cancel()
+
+			_, err := conn.ExecuteContext(ctx, "second")
+			second <- err
+		}()
+
+		time.Sleep(100 * time.Millisecond)
+
+		request := &rcon.Packet{}
+		if _, err := request.ReadFrom(server); err != nil {
+			t.Fatalf("got err %q, want %v", err, nil)
+		}
+
+		if err := <-second; !errors.Is(err, context.DeadlineExceeded) {
+			t.Errorf("got err %q, want %q", err, context.DeadlineExceeded)
+		}
+
+		rcon.NewPacket(rcon.SERVERDATA_RESPONSE_VALUE, request.ID, "").WriteTo(server)
+
+		if err := <-first; err != nil {
+			t.Fatalf("got err %q, want %v", err, nil)
+		}
+
+		// Conn is usable after the command which was not sent.
+		go func() {
+			request := &rcon.Packet{}
+			request.ReadFrom(server)
+			rcon.NewPacket(rcon.SERVERDATA_RESPONSE_VALUE, request.ID, "").WriteTo(server)
+		}()
+
+		if _, err := conn.Execute("third"); err != nil {
+			t.Fatalf("got err %q, want %v", err, nil)
+		}
+	})
+}
+
 func TestConn_Execute(t *testing.T) {
 	server := rcontest.NewUnstartedServer()
 	server.Settings.Password = "password"
@@ -314,6 +709,53 @@ func TestConn_Execute(t *testing.T) {
 		}
 	})
 
+	t.Run("multi packet mirror", func(t *testing.T) {
+		conn, err := rcon.Dial(server.Addr(), "password", rcon.SetMultiPacket(rcon.MultiPacketMirror))
+		if err != nil {
+			t.Fatalf("got err %q, want %v", err, nil)
+		}
+		defer conn.Close()
+
+		resultWant := strings.Repeat("a", 5000) + strings.Repeat("b", 5000)
+
+		for i := 0; i < 2; i++ {
+			result, err := conn.Execute("long")
+			if err != nil {
+				t.Fatalf("got err %q, want %v", err, nil)
+			}
+
+			if result != resultWant {
+				t.Fatalf("got result len %d, want %d", len(result), len(resultWant))
+			}
+		}
+
+		result, err := conn.Execute("help")
+		if err != nil {
+			t.Fatalf("got err %q, want %v", err, nil)
+		}
+
+		if resultWant := "lorem ipsum dolor sit amet"; result != resultWant {
+			t.Fatalf("got result %q, want %q", result, resultWant)
+		}
+	})
+
+	t.Run("multi packet none", func(t *testing.T) {
+		conn, err := rcon.Dial(server.Addr(), "password")
+		if err != nil {
+			t.Fatalf("got err %q, want %v", err, nil)
+		}
+		defer conn.Close()
+
+		result, err := conn.Execute("long")
+		if err != nil {
+			t.Fatalf("got err %q, want %v", err, nil)
+		}
+
+		if len(result) != rcontest.MaxResponseBodySize {
+			t.Fatalf("got result len %d, want %d", len(result), rcontest.MaxResponseBodySize)
+		}
+	})
+
 	if run := getVar("TEST_PZ_SERVER", "false"); run == "true" {
 		addr := getVar("TEST_PZ_SERVER_ADDR", "127.0.0.1:16260")
 		password := getVar("TEST_PZ_SERVER_PASSWORD", "docker")
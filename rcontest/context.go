@@ -28,3 +28,29 @@ func (c *Context) Conn() net.Conn {
 func (c *Context) Request() *rcon.Packet {
 	return c.request
 }
+
+// WriteResponse writes body to the conn as SERVERDATA_RESPONSE_VALUE packets
+// with the request ID. Body larger than Settings.MaxResponseBodySize is split
+// into multiple packets like Source and Minecraft servers do.
+func (c *Context) WriteResponse(body string) error {
+	size := c.server.Settings.MaxResponseBodySize
+	if size == 0 {
+		size = MaxResponseBodySize
+	}
+
+	for {
+		chunk := body
+		if len(chunk) > size {
+			chunk = chunk[:size]
+		}
+
+		if _, err := rcon.NewPacket(rcon.SERVERDATA_RESPONSE_VALUE, c.request.ID, chunk).WriteTo(c.conn); err != nil {
+			return err
+		}
+
+		body = body[len(chunk):]
+		if body == "" {
+			return nil
+		}
+	}
+}
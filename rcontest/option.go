@@ -23,3 +23,11 @@ func SetCommandHandler(handler HandlerFunc) Option {
 		s.SetCommandHandler(handler)
 	}
 }
+
+// SetResponseValueHandler injects HandlerFunc with SERVERDATA_RESPONSE_VALUE
+// requests processing.
+func SetResponseValueHandler(handler HandlerFunc) Option {
+	return func(s *Server) {
+		s.SetResponseValueHandler(handler)
+	}
+}
@@ -2,24 +2,57 @@
 package rcontest
 
 import (
+	"bytes"
+	"crypto/tls"
+	"crypto/x509"
 	"errors"
 	"fmt"
 	"io"
 	"net"
 	"sync"
+	"syscall"
 	"time"
 
 	"github.com/gorcon/rcon"
 )
 
+// MaxResponseBodySize is the maximum size of SERVERDATA_RESPONSE_VALUE packet
+// body sent by Server. Larger responses are split into multiple packets.
+const MaxResponseBodySize = int(rcon.MaxPacketSize - rcon.MinPacketSize)
+
+// Minecraft server limits used by NewMinecraftServer.
+const (
+	// MinecraftReadBufferSize is the size of the buffer Minecraft server
+	// reads requests into.
+	MinecraftReadBufferSize = 1460
+
+	// MinecraftMaxResponseBodySize is the maximum size of response packet
+	// body sent by Minecraft server.
+	MinecraftMaxResponseBodySize = 4096
+)
+
+// ErrRequestRejected is returned when the request can't be read into
+// Settings.ReadBufferSize with a single read. The server closes the
+// connection in this case.
+var ErrRequestRejected = errors.New("request rejected")
+
 // Server is an RCON server listening on a system-chosen port on the
 // local loopback interface, for use in end-to-end RCON tests.
 type Server struct {
-	Settings       Settings
-	Listener       net.Listener
+	Settings Settings
+	Listener net.Listener
+
+	// TLS is the optional TLS configuration, populated with a new config
+	// after TLS is started. If set on an unstarted server before StartTLS
+	// is called, existing fields are used, for example ClientAuth and
+	// ClientCAs to require client certificates.
+	TLS *tls.Config
+
 	addr           string
+	certificate    *x509.Certificate
 	authHandler    HandlerFunc
 	commandHandler HandlerFunc
+	valueHandler   HandlerFunc
 	connections    map[net.Conn]struct{}
 	quit           chan bool
 	wg             sync.WaitGroup
@@ -32,6 +65,16 @@ type Settings struct {
 	Password             string
 	AuthResponseDelay    time.Duration
 	CommandResponseDelay time.Duration
+
+	// ReadBufferSize makes the server read each request with a single read
+	// into a buffer of this size, like Minecraft server does. Requests which
+	// don't fit are rejected, the rest of the read data is discarded.
+	// Zero means requests are read from the stream as is.
+	ReadBufferSize int
+
+	// MaxResponseBodySize is the maximum size of packet body written by
+	// Context.WriteResponse. Zero means MaxResponseBodySize.
+	MaxResponseBodySize int
 }
 
 // HandlerFunc defines a function to serve RCON requests.
@@ -45,13 +88,42 @@ func AuthHandler(c *Context) {
 		_, _ = rcon.NewPacket(rcon.SERVERDATA_RESPONSE_VALUE, c.Request().ID, "").WriteTo(c.Conn())
 
 		// Than write SERVERDATA_AUTH_RESPONSE packet to allow authHandler success.
-		_, _ = rcon.NewPacket(rcon.SERVERDATA_AUTH_RESPONSE, rcon.SERVERDATA_AUTH_ID, "").WriteTo(c.Conn())
+		_, _ = rcon.NewPacket(rcon.SERVERDATA_AUTH_RESPONSE, c.Request().ID, "").WriteTo(c.Conn())
 	} else {
 		// If authentication was failed, the ID must be assigned to -1.
 		_, _ = rcon.NewPacket(rcon.SERVERDATA_AUTH_RESPONSE, -1, string([]byte{0x00})).WriteTo(c.Conn())
 	}
 }
 
+// MirrorHandler responses to SERVERDATA_RESPONSE_VALUE request like a Source
+// server does: it mirrors back an empty SERVERDATA_RESPONSE_VALUE packet and
+// then sends one more packet with 0x00000001 body and the same ID.
+func MirrorHandler(c *Context) {
+	_, _ = rcon.NewPacket(rcon.SERVERDATA_RESPONSE_VALUE, c.Request().ID, "").WriteTo(c.Conn())
+	_, _ = rcon.NewPacket(rcon.SERVERDATA_RESPONSE_VALUE, c.Request().ID, string([]byte{0x00, 0x01, 0x00, 0x00})).
+		WriteTo(c.Conn())
+}
+
+// MinecraftAuthHandler checks authorisation data and responses with
+// SERVERDATA_AUTH_RESPONSE packet only, like Minecraft server does.
+func MinecraftAuthHandler(c *Context) {
+	id := c.Request().ID
+	if c.Request().Body() != c.Server().Settings.Password {
+		id = -1
+	}
+
+	_, _ = rcon.NewPacket(rcon.SERVERDATA_AUTH_RESPONSE, id, "").WriteTo(c.Conn())
+}
+
+// UnknownRequestHandler responses to the request with an error message, like
+// Minecraft server does for packet types other than SERVERDATA_AUTH and
+// SERVERDATA_EXECCOMMAND.
+func UnknownRequestHandler(c *Context) {
+	body := fmt.Sprintf("Unknown request %x", c.Request().Type)
+
+	_, _ = rcon.NewPacket(rcon.SERVERDATA_RESPONSE_VALUE, c.Request().ID, body).WriteTo(c.Conn())
+}
+
 // EmptyHandler responses with empty body. Is used when start RCON Server with nil
 // commandHandler.
 func EmptyHandler(c *Context) {
@@ -76,6 +148,33 @@ func NewServer(options ...Option) *Server {
 	return server
 }
 
+// NewMinecraftServer returns a running RCON Server which mimics vanilla
+// Minecraft server: it reads only one request at once, rejects requests
+// longer than MinecraftReadBufferSize, splits responses into
+// MinecraftMaxResponseBodySize packets without any terminator and responses
+// to unknown requests with an error message.
+// The caller should call Close when finished, to shut it down.
+func NewMinecraftServer(options ...Option) *Server {
+	options = append([]Option{
+		SetAuthHandler(MinecraftAuthHandler),
+		SetResponseValueHandler(UnknownRequestHandler),
+	}, options...)
+
+	server := NewUnstartedServer(options...)
+
+	if server.Settings.ReadBufferSize == 0 {
+		server.Settings.ReadBufferSize = MinecraftReadBufferSize
+	}
+
+	if server.Settings.MaxResponseBodySize == 0 {
+		server.Settings.MaxResponseBodySize = MinecraftMaxResponseBodySize
+	}
+
+	server.Start()
+
+	return server
+}
+
 // NewUnstartedServer returns a new Server but doesn't start it.
 // After changing its configuration, the caller should call Start.
 // The caller should call Close when finished, to shut it down.
@@ -84,6 +183,7 @@ func NewUnstartedServer(options ...Option) *Server {
 		Listener:       newLocalListener(),
 		authHandler:    AuthHandler,
 		commandHandler: EmptyHandler,
+		valueHandler:   MirrorHandler,
 		connections:    make(map[net.Conn]struct{}),
 		quit:           make(chan bool),
 	}
@@ -105,6 +205,12 @@ func (s *Server) SetCommandHandler(handler HandlerFunc) {
 	s.commandHandler = handler
 }
 
+// SetResponseValueHandler injects HandlerFunc with SERVERDATA_RESPONSE_VALUE
+// requests processing.
+func (s *Server) SetResponseValueHandler(handler HandlerFunc) {
+	s.valueHandler = handler
+}
+
 // Start starts a server from NewUnstartedServer.
 func (s *Server) Start() {
 	if s.addr != "" {
@@ -145,6 +251,10 @@ func (s *Server) Addr() string {
 func (s *Server) NewContext(conn net.Conn) (*Context, error) {
 	ctx := Context{server: s, conn: conn, request: &rcon.Packet{}}
 
+	if s.Settings.ReadBufferSize != 0 {
+		return &ctx, s.readBuffered(&ctx)
+	}
+
 	if _, err := ctx.request.ReadFrom(conn); err != nil {
 		return &ctx, fmt.Errorf("rcontest: %w", err)
 	}
@@ -152,6 +262,23 @@ func (s *Server) NewContext(conn net.Conn) (*Context, error) {
 	return &ctx, nil
 }
 
+// readBuffered reads the request with a single read into a buffer of
+// Settings.ReadBufferSize.
+func (s *Server) readBuffered(ctx *Context) error {
+	buffer := make([]byte, s.Settings.ReadBufferSize)
+
+	n, err := ctx.conn.Read(buffer)
+	if err != nil {
+		return fmt.Errorf("rcontest: %w", err)
+	}
+
+	if _, err := ctx.request.ReadFrom(bytes.NewReader(buffer[:n])); err != nil {
+		return fmt.Errorf("%w: %w", ErrRequestRejected, err)
+	}
+
+	return nil
+}
+
 // serve handles incoming requests until a stop signal is given with Close.
 func (s *Server) serve() {
 	for {
@@ -192,10 +319,21 @@ func (s *Server) handle(conn net.Conn) {
 		s.wg.Done()
 	}()
 
+	if tlsConn, ok := conn.(*tls.Conn); ok {
+		// Client may reject the server certificate and vice versa.
+		if err := tlsConn.Handshake(); err != nil {
+			return
+		}
+	}
+
 	for {
 		ctx, err := s.NewContext(conn)
 		if err != nil {
-			if !errors.Is(err, io.EOF) {
+			// Client may close the conn without reading all responses,
+			// handler may close the conn to simulate server failure.
+			// Rejected requests close the conn as well.
+			if !errors.Is(err, io.EOF) && !errors.Is(err, syscall.ECONNRESET) && !errors.Is(err, net.ErrClosed) &&
+				!errors.Is(err, ErrRequestRejected) {
 				panic(fmt.Errorf("failed read request: %w", err))
 			}
 
@@ -215,6 +353,8 @@ func (s *Server) handle(conn net.Conn) {
 			}
 
 			s.commandHandler(ctx)
+		case rcon.SERVERDATA_RESPONSE_VALUE:
+			s.valueHandler(ctx)
 		}
 	}
 }
@@ -234,7 +374,7 @@ func (s *Server) closeConn(conn net.Conn) {
 	s.mu.Lock()
 	defer s.mu.Unlock()
 
-	if err := conn.Close(); err != nil {
+	if err := conn.Close(); err != nil && !errors.Is(err, net.ErrClosed) {
 		panic(fmt.Errorf("close conn error: %w", err))
 	}
 
@@ -2,6 +2,7 @@ package rcontest_test
 
 import (
 	"errors"
+	"strings"
 	"testing"
 	"time"
 
@@ -82,6 +83,46 @@ func TestNewServer(t *testing.T) {
 		}
 	})
 
+	t.Run("split response", func(t *testing.T) {
+		server := rcontest.NewServer(
+			rcontest.SetSettings(rcontest.Settings{Password: "password"}),
+			rcontest.SetCommandHandler(func(c *rcontest.Context) {
+				c.WriteResponse(strings.Repeat("x", 2*rcontest.MaxResponseBodySize+1))
+			}),
+		)
+		defer server.Close()
+
+		client, err := rcon.Dial(server.Addr(), "password", rcon.SetMultiPacket(rcon.MultiPacketMirror))
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer client.Close()
+
+		response, err := client.Execute("cvarlist")
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if len(response) != 2*rcontest.MaxResponseBodySize+1 {
+			t.Errorf("got response len %d, want %d", len(response), 2*rcontest.MaxResponseBodySize+1)
+		}
+	})
+
+	t.Run("tls", func(t *testing.T) {
+		server := rcontest.NewTLSServer(rcontest.SetSettings(rcontest.Settings{Password: "password"}))
+		defer server.Close()
+
+		client, err := rcon.DialTLS(server.Addr(), "password", server.ClientTLSConfig())
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer client.Close()
+
+		if _, err := client.Execute("whatever"); err != nil {
+			t.Fatal(err)
+		}
+	})
+
 	t.Run("empty handler", func(t *testing.T) {
 		server := rcontest.NewServer()
 		defer server.Close()
@@ -102,3 +143,50 @@ func TestNewServer(t *testing.T) {
 		}
 	})
 }
+
+func TestNewMinecraftServer(t *testing.T) {
+	server := rcontest.NewMinecraftServer(
+		rcontest.SetSettings(rcontest.Settings{Password: "password"}),
+		rcontest.SetCommandHandler(func(c *rcontest.Context) {
+			c.WriteResponse(strings.Repeat("x", 2*rcontest.MinecraftMaxResponseBodySize+1))
+		}),
+	)
+	defer server.Close()
+
+	t.Run("authentication failed", func(t *testing.T) {
+		_, err := rcon.Dial(server.Addr(), "wrong", rcon.SetDialect(rcon.DialectMinecraft))
+		if !errors.Is(err, rcon.ErrAuthFailed) {
+			t.Fatalf("got err %q, want %q", err, rcon.ErrAuthFailed)
+		}
+	})
+
+	t.Run("fragmented response", func(t *testing.T) {
+		client, err := rcon.Dial(server.Addr(), "password", rcon.SetDialect(rcon.DialectMinecraft))
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer client.Close()
+
+		response, err := client.Execute("list")
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if len(response) != 2*rcontest.MinecraftMaxResponseBodySize+1 {
+			t.Errorf("got response len %d, want %d", len(response), 2*rcontest.MinecraftMaxResponseBodySize+1)
+		}
+	})
+
+	t.Run("request rejected", func(t *testing.T) {
+		client, err := rcon.Dial(server.Addr(), "password",
+			rcon.SetDialect(rcon.DialectMinecraft), rcon.SetMaxCommandLen(2000))
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer client.Close()
+
+		if _, err := client.Execute(strings.Repeat("a", 1447)); err == nil {
+			t.Error("got no error, want connection closed")
+		}
+	})
+}
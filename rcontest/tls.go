@@ -0,0 +1,114 @@
+package rcontest
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"fmt"
+	"math/big"
+	"net"
+	"time"
+)
+
+// GenerateCertificate returns a new self-signed certificate valid for hosts,
+// which are IP addresses or DNS names. The certificate can be used both as
+// server and client certificate and as its own certificate authority.
+func GenerateCertificate(hosts ...string) (tls.Certificate, error) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		return tls.Certificate{}, fmt.Errorf("rcontest: %w", err)
+	}
+
+	serial, err := rand.Int(rand.Reader, new(big.Int).Lsh(big.NewInt(1), 128))
+	if err != nil {
+		return tls.Certificate{}, fmt.Errorf("rcontest: %w", err)
+	}
+
+	template := x509.Certificate{
+		SerialNumber:          serial,
+		Subject:               pkix.Name{Organization: []string{"rcontest"}},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(24 * time.Hour),
+		KeyUsage:              x509.KeyUsageDigitalSignature | x509.KeyUsageCertSign,
+		ExtKeyUsage:           []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth, x509.ExtKeyUsageClientAuth},
+		BasicConstraintsValid: true,
+		IsCA:                  true,
+	}
+
+	for _, host := range hosts {
+		if ip := net.ParseIP(host); ip != nil {
+			template.IPAddresses = append(template.IPAddresses, ip)
+		} else {
+			template.DNSNames = append(template.DNSNames, host)
+		}
+	}
+
+	der, err := x509.CreateCertificate(rand.Reader, &template, &template, &key.PublicKey, key)
+	if err != nil {
+		return tls.Certificate{}, fmt.Errorf("rcontest: %w", err)
+	}
+
+	leaf, err := x509.ParseCertificate(der)
+	if err != nil {
+		return tls.Certificate{}, fmt.Errorf("rcontest: %w", err)
+	}
+
+	return tls.Certificate{Certificate: [][]byte{der}, PrivateKey: key, Leaf: leaf}, nil
+}
+
+// NewTLSServer returns a running RCON Server listening with TLS.
+// The caller should call Close when finished, to shut it down.
+func NewTLSServer(options ...Option) *Server {
+	server := NewUnstartedServer(options...)
+	server.StartTLS()
+
+	return server
+}
+
+// StartTLS starts TLS on a server from NewUnstartedServer. The server uses
+// the certificate from Server.TLS if it is set, otherwise a self-signed
+// certificate for the loopback interface is generated.
+func (s *Server) StartTLS() {
+	if s.addr != "" {
+		panic("server already started")
+	}
+
+	if s.TLS == nil {
+		s.TLS = &tls.Config{MinVersion: tls.VersionTLS12}
+	}
+
+	if len(s.TLS.Certificates) == 0 {
+		cert, err := GenerateCertificate("127.0.0.1", "::1", "localhost")
+		if err != nil {
+			panic(fmt.Sprintf("rcontest: failed to generate certificate: %v", err))
+		}
+
+		s.TLS.Certificates = []tls.Certificate{cert}
+	}
+
+	s.certificate = s.TLS.Certificates[0].Leaf
+	s.Listener = tls.NewListener(s.Listener, s.TLS)
+	s.Start()
+}
+
+// Certificate returns the certificate used by the server started with
+// StartTLS, or nil if the server doesn't use TLS.
+func (s *Server) Certificate() *x509.Certificate {
+	return s.certificate
+}
+
+// ClientTLSConfig returns TLS configuration for clients which trusts the
+// server certificate, or nil if the server doesn't use TLS.
+func (s *Server) ClientTLSConfig() *tls.Config {
+	if s.certificate == nil {
+		return nil
+	}
+
+	roots := x509.NewCertPool()
+	roots.AddCert(s.certificate)
+
+	return &tls.Config{RootCAs: roots, MinVersion: tls.VersionTLS12}
+}
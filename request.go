@@ -0,0 +1,393 @@
+package rcon
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"log/slog"
+	"math"
+	"os"
+	"time"
+)
+
+// trailerBody is the body of the packet which Source servers send right after
+// the mirrored terminator packet.
+var trailerBody = []byte{0x00, 0x01, 0x00, 0x00}
+
+// request is a command awaiting response from the server.
+type request struct {
+	// id is the packet ID the command is sent with.
+	id int32
+
+	// terminatorID is the packet ID of the empty SERVERDATA_RESPONSE_VALUE
+	// packet sent after the command with MultiPacketMirror or
+	// MultiPacketSentinel strategy. It is zero when the terminator packet is
+	// not used.
+	terminatorID int32
+
+	// first is closed when the first response packet is received with
+	// MultiPacketSentinel strategy. It is nil for other strategies.
+	first chan struct{}
+
+	// expires is the time the response must be received before. It is zero
+	// when the read deadline is disabled.
+	expires time.Time
+
+	// body and err are set before done is closed.
+	body []byte
+	err  error
+	done chan struct{}
+}
+
+// countingReader counts bytes read from the underlying reader.
+type countingReader struct {
+	r io.Reader
+	n int
+}
+
+// Read implements io.Reader.
+func (cr *countingReader) Read(p []byte) (int, error) {
+	n, err := cr.r.Read(p)
+	cr.n += n
+
+	return n, err
+}
+
+// register allocates packet IDs for a new request and adds it to the pending
+// requests. It fails with the error which stopped the background reader,
+// unless Conn was closed by Close, in which case writing reports the error.
+func (c *Conn) register(strategy MultiPacketStrategy) (*request, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.err != nil && !c.closed {
+		return nil, c.err
+	}
+
+	req := &request{id: c.nextID(), done: make(chan struct{})}
+	c.pending[req.id] = req
+
+	if strategy != MultiPacketNone {
+		req.terminatorID = c.nextID()
+		c.pending[req.terminatorID] = req
+	}
+
+	if strategy == MultiPacketSentinel {
+		req.first = make(chan struct{})
+	}
+
+	if c.settings.deadline != 0 {
+		req.expires = time.Now().Add(c.settings.deadline)
+	}
+
+	return req, nil
+}
+
+// unregister removes the abandoned request from the pending requests, so its
+// response is discarded as stale.
+func (c *Conn) unregister(req *request) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.remove(req)
+	c.updateReadDeadline()
+}
+
+// remove removes req from the pending requests. c.mu must be held.
+func (c *Conn) remove(req *request) {
+	delete(c.pending, req.id)
+
+	if req.terminatorID != 0 {
+		delete(c.pending, req.terminatorID)
+	}
+}
+
+// complete removes req from the pending requests and wakes up the waiting
+// goroutine. c.mu must be held.
+func (c *Conn) complete(req *request, err error) {
+	c.remove(req)
+
+	req.err = err
+	close(req.done)
+}
+
+// fail stops Conn after an unrecoverable error: all pending requests are
+// failed with err and the conn is closed.
+func (c *Conn) fail(err error) {
+	c.mu.Lock()
+
+	if c.err == nil {
+		c.err = err
+
+		if !c.closed {
+			c.settings.debug(context.Background(), "rcon: connection failed", errorAttr(err))
+		}
+	}
+
+	for id, req := range c.pending {
+		if id == req.id {
+			c.complete(req, err)
+		}
+	}
+
+	for _, s := range c.subscriptions {
+		s.close()
+	}
+
+	c.subscriptions = nil
+	c.mu.Unlock()
+
+	_ = c.conn.Close()
+}
+
+// isClosed reports whether Close was called.
+func (c *Conn) isClosed() bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return c.closed
+}
+
+// broken reports whether Conn was stopped by an unrecoverable error.
+func (c *Conn) broken() bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return c.err != nil
+}
+
+// failure returns the error which stopped Conn, or nil if it is not broken.
+func (c *Conn) failure() error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return c.err
+}
+
+// nextID allocates a new positive packet ID. IDs are increased monotonically
+// and wrap around to 1 after math.MaxInt32. c.mu must be held once the
+// background reader is started.
+func (c *Conn) nextID() int32 {
+	if c.lastID == math.MaxInt32 {
+		c.lastID = 0
+	}
+
+	c.lastID++
+
+	return c.lastID
+}
+
+// isStale reports whether id was allocated by Conn for a previous request.
+// c.mu must be held.
+func (c *Conn) isStale(id int32) bool {
+	return id > 0 && id <= c.lastID
+}
+
+// oldest returns the pending request with the lowest packet ID or nil if
+// there are no pending requests. c.mu must be held.
+func (c *Conn) oldest() *request {
+	var oldest *request
+
+	for _, req := range c.pending {
+		if oldest == nil || req.id < oldest.id {
+			oldest = req
+		}
+	}
+
+	return oldest
+}
+
+// updateReadDeadline sets the conn read deadline to the earliest expiration
+// time of pending requests or clears it if there are no such requests.
+// c.mu must be held.
+func (c *Conn) updateReadDeadline() {
+	if c.settings.deadline == 0 {
+		return
+	}
+
+	var deadline time.Time
+
+	for _, req := range c.pending {
+		if deadline.IsZero() || req.expires.Before(deadline) {
+			deadline = req.expires
+		}
+	}
+
+	_ = c.conn.SetReadDeadline(deadline)
+}
+
+// expire fails pending requests which expired with err. c.mu must be held.
+func (c *Conn) expire(err error) {
+	now := time.Now()
+
+	for id, req := range c.pending {
+		if id == req.id && !req.expires.After(now) {
+			c.complete(req, err)
+		}
+	}
+}
+
+// readLoop reads packets from the conn and dispatches them to pending requests
+// until an unrecoverable error occurs.
+func (c *Conn) readLoop() {
+	defer close(c.done)
+
+	var previous *Packet
+
+	reader := &countingReader{r: c.conn}
+
+	for {
+		reader.n = 0
+
+		packet := &Packet{encoding: c.settings.encoding}
+		_, err := packet.ReadFrom(reader)
+
+		switch {
+		case err == nil || errors.Is(err, ErrInvalidPacketPadding):
+			// The whole packet was read, so the stream is still consistent.
+			c.lastRead.Store(time.Now().UnixNano())
+			c.settings.metrics.ObserveRead(c.address, reader.n, err)
+			c.settings.debug(context.Background(), "rcon: read packet", packetAttr(packet))
+			c.handle(packet, previous, err)
+			previous = packet
+		case errors.Is(err, os.ErrDeadlineExceeded) && reader.n == 0:
+			// Nothing was read, so the stream is still consistent.
+			c.settings.debug(context.Background(), "rcon: read deadline exceeded")
+			c.settings.metrics.ObserveRead(c.address, 0, err)
+			c.mu.Lock()
+			c.expire(err)
+			c.updateReadDeadline()
+			c.mu.Unlock()
+		default:
+			if !c.isClosed() {
+				c.settings.metrics.ObserveRead(c.address, reader.n, err)
+			}
+
+			c.fail(err)
+
+			return
+		}
+	}
+}
+
+// handle applies the dialect packet quirks and dispatches the packet.
+func (c *Conn) handle(packet *Packet, previous *Packet, err error) {
+	dialect := slog.String("dialect", c.settings.dialect.Name())
+
+	switch c.settings.dialect.HandlePacket(packet, previous) {
+	case PacketSkip:
+		// Packets with IDs which are never allocated can't be matched to a
+		// request, so they are still delivered to subscriptions, e.g. Rust
+		// console output with type 4.
+		if packet.ID > 0 {
+			c.settings.debug(context.Background(), "rcon: skip packet", dialect, slog.Int("id", int(packet.ID)))
+
+			return
+		}
+	case PacketAssign:
+		c.mu.Lock()
+		if req := c.oldest(); req != nil {
+			c.settings.debug(context.Background(), "rcon: assign packet", dialect,
+				slog.Int("id", int(packet.ID)), slog.Int("request_id", int(req.id)))
+
+			packet.ID = req.id
+		}
+		c.mu.Unlock()
+	case PacketAccept:
+	}
+
+	c.dispatch(packet, err)
+}
+
+// dispatch passes the packet to the pending request it belongs to. Packets of
+// abandoned requests are discarded as stale. Packets with IDs which were never
+// allocated are unsolicited, they are delivered to matching subscriptions.
+// If there are no such subscriptions, packets with IDs which are never
+// allocated, like -1 and 0, are dropped, while packets with positive IDs
+// break the protocol and fail the oldest pending request with
+// ErrInvalidPacketID.
+func (c *Conn) dispatch(packet *Packet, err error) {
+	var (
+		stale    bool
+		handlers []*Subscription
+	)
+
+	c.mu.Lock()
+
+	req, ok := c.pending[packet.ID]
+
+	switch {
+	case ok && packet.ID == req.id:
+		req.body = append(req.body, packet.body...)
+
+		if req.first != nil {
+			select {
+			case <-req.first:
+			default:
+				close(req.first)
+			}
+		}
+
+		if req.terminatorID == 0 || err != nil {
+			c.complete(req, err)
+		} else if !req.expires.IsZero() {
+			// More packets are expected.
+			req.expires = time.Now().Add(c.settings.deadline)
+		}
+	case ok:
+		// Response to the terminator packet.
+		c.complete(req, err)
+	case c.isStale(packet.ID):
+		stale = !bytes.Equal(packet.body, trailerBody)
+
+		if stale {
+			c.settings.debug(context.Background(), "rcon: stale packet", slog.Int("id", int(packet.ID)))
+		}
+	default:
+		subs := c.subscribers(packet)
+
+		for _, s := range subs {
+			if s.handler != nil {
+				handlers = append(handlers, s)
+			} else {
+				s.deliver(packet)
+			}
+		}
+
+		if len(subs) == 0 {
+			c.unexpected(packet)
+		}
+	}
+
+	c.updateReadDeadline()
+	c.mu.Unlock()
+
+	if stale && c.settings.staleHandler != nil {
+		c.settings.staleHandler(packet)
+	}
+
+	// Handlers are called without the lock.
+	for _, s := range handlers {
+		s.deliver(packet)
+	}
+}
+
+// unexpected handles the packet which doesn't belong to any request or
+// subscription. c.mu must be held.
+func (c *Conn) unexpected(packet *Packet) {
+	if packet.ID <= 0 {
+		c.settings.debug(context.Background(), "rcon: unsolicited packet dropped",
+			slog.Int("id", int(packet.ID)), slog.Int("type", int(packet.Type)))
+
+		return
+	}
+
+	if req := c.oldest(); req != nil {
+		c.settings.debug(context.Background(), "rcon: unexpected packet",
+			slog.Int("id", int(packet.ID)), slog.Int("request_id", int(req.id)))
+
+		req.body = append(req.body, packet.body...)
+		c.complete(req, ErrInvalidPacketID)
+	}
+}
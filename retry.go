@@ -0,0 +1,137 @@
+package rcon
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"regexp"
+	"strings"
+	"time"
+)
+
+// Default retry policy backoff.
+const (
+	DefaultMinRetryBackoff = 50 * time.Millisecond
+	DefaultMaxRetryBackoff = time.Second
+)
+
+// RetryPolicy defines how Execute retries failed commands on the same Conn.
+// Commands are retried only if Safe reports they are safe to retry and they
+// failed with a retryable error. A timed out command may have been executed
+// by the server, so only commands without side effects should be safe.
+// Retries stop when the context is done or Conn is broken, Client reconnects
+// in that case.
+type RetryPolicy struct {
+	// MaxAttempts is the maximum number of attempts including the first one.
+	// Commands are not retried if it is less than 2.
+	MaxAttempts int
+
+	// MinBackoff and MaxBackoff are the delays before the first retry and
+	// the limit of the delay, which is doubled after each attempt and
+	// randomized by up to a half. DefaultMinRetryBackoff and
+	// DefaultMaxRetryBackoff are used if they are zero.
+	MinBackoff time.Duration
+	MaxBackoff time.Duration
+
+	// Retryable reports whether the command can be retried after err.
+	// IsRetryable is used if nil.
+	Retryable func(err error) bool
+
+	// Safe reports whether the command is safe to retry. No command is safe
+	// if nil, so commands are retried only when it is set.
+	Safe func(command string) bool
+
+	// Unsafe reports whether the command must not be retried even if Safe
+	// reports it is safe, e.g. ban or give. No command is unsafe if nil.
+	Unsafe func(command string) bool
+}
+
+// IsRetryable reports whether err is transient: a read or write timeout or
+// a response for another request after a hiccup.
+func IsRetryable(err error) bool {
+	return errors.Is(err, os.ErrDeadlineExceeded) ||
+		errors.Is(err, ErrInvalidPacketID) ||
+		errors.Is(err, ErrInvalidPacketPadding)
+}
+
+// MatchPrefix returns a function which reports whether the command starts
+// with one of the prefixes, ignoring case. It can be used for
+// RetryPolicy.Safe and RetryPolicy.Unsafe.
+func MatchPrefix(prefixes ...string) func(command string) bool {
+	return func(command string) bool {
+		for _, prefix := range prefixes {
+			if len(command) >= len(prefix) && strings.EqualFold(command[:len(prefix)], prefix) {
+				return true
+			}
+		}
+
+		return false
+	}
+}
+
+// MatchRegexp returns a function which reports whether the command matches
+// re. It can be used for RetryPolicy.Safe and RetryPolicy.Unsafe.
+func MatchRegexp(re *regexp.Regexp) func(command string) bool {
+	return re.MatchString
+}
+
+// IsSafe reports whether the command is safe to retry by the policy.
+func (p RetryPolicy) IsSafe(command string) bool {
+	return p.Safe != nil && p.Safe(command) && (p.Unsafe == nil || !p.Unsafe(command))
+}
+
+// retryable reports whether the command failed with err can be retried.
+func (p RetryPolicy) retryable(command string, err error) bool {
+	retryable := p.Retryable
+	if retryable == nil {
+		retryable = IsRetryable
+	}
+
+	return retryable(err) && p.IsSafe(command)
+}
+
+// backoff returns the delay before the retry after attempt.
+func (p RetryPolicy) backoff(attempt int) time.Duration {
+	minDelay, maxDelay := p.MinBackoff, p.MaxBackoff
+
+	if minDelay == 0 {
+		minDelay = DefaultMinRetryBackoff
+	}
+
+	if maxDelay == 0 {
+		maxDelay = DefaultMaxRetryBackoff
+	}
+
+	return backoff(minDelay, maxDelay, attempt)
+}
+
+// executeWithRetry executes the command and retries it by the retry policy.
+func (c *Conn) executeWithRetry(ctx context.Context, command string, body string) (*Response, error) {
+	policy := c.settings.retryPolicy
+
+	for attempt := 0; ; attempt++ {
+		if err := c.settings.limiter.wait(ctx, command); err != nil {
+			return &Response{}, err
+		}
+
+		response, err := c.execute(ctx, SERVERDATA_EXECCOMMAND, body, c.settings.multiPacket)
+		if err == nil || attempt+1 >= policy.MaxAttempts || !policy.retryable(command, err) ||
+			ctx.Err() != nil || c.broken() {
+			return response, err
+		}
+
+		c.settings.debug(ctx, "rcon: retry command", slog.Int("attempt", attempt+1), errorAttr(err))
+
+		timer := time.NewTimer(policy.backoff(attempt))
+
+		select {
+		case <-timer.C:
+		case <-ctx.Done():
+			timer.Stop()
+
+			return response, fmt.Errorf("rcon: %w", ctx.Err())
+		}
+	}
+}
@@ -0,0 +1,169 @@
+package rcon_test
+
+import (
+	"errors"
+	"regexp"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/gorcon/rcon"
+	"github.com/gorcon/rcon/rcontest"
+)
+
+func TestSetRetryPolicy(t *testing.T) {
+	var attempts atomic.Int32
+
+	server := rcontest.NewServer(
+		rcontest.SetSettings(rcontest.Settings{Password: "password"}),
+		rcontest.SetCommandHandler(func(c *rcontest.Context) {
+			switch attempt := attempts.Add(1); {
+			case c.Request().Body() == "crash" && attempt == 1:
+				c.Conn().Close()
+			case c.Request().Body() == "slow" && attempt == 1:
+				// No response to the first attempt.
+			case attempt < 3 && c.Request().Body() != "slow" && c.Request().Body() != "crash":
+				// Response for another request.
+				rcon.NewPacket(rcon.SERVERDATA_RESPONSE_VALUE, 1000, "").WriteTo(c.Conn())
+			default:
+				rcon.NewPacket(rcon.SERVERDATA_RESPONSE_VALUE, c.Request().ID, c.Request().Body()).WriteTo(c.Conn())
+			}
+		}),
+	)
+	defer server.Close()
+
+	policy := rcon.RetryPolicy{
+		MaxAttempts: 3,
+		MinBackoff:  time.Millisecond,
+		MaxBackoff:  time.Millisecond,
+		Safe:        rcon.MatchRegexp(regexp.MustCompile(`^(status|list|slow|crash)\b`)),
+		Unsafe:      rcon.MatchPrefix("ban"),
+	}
+
+	dial := func(t *testing.T, options ...rcon.Option) *rcon.Conn {
+		t.Helper()
+
+		attempts.Store(0)
+
+		conn, err := rcon.Dial(server.Addr(), "password", options...)
+		if err != nil {
+			t.Fatalf("got err %q, want %v", err, nil)
+		}
+
+		return conn
+	}
+
+	t.Run("retryable error", func(t *testing.T) {
+		conn := dial(t, rcon.SetRetryPolicy(policy))
+		defer conn.Close()
+
+		result, err := conn.Execute("status")
+		if err != nil {
+			t.Fatalf("got err %q, want %v", err, nil)
+		}
+
+		if result != "status" || attempts.Load() != 3 {
+			t.Errorf("got result %q after %d attempts, want %q after 3", result, attempts.Load(), "status")
+		}
+	})
+
+	t.Run("timeout", func(t *testing.T) {
+		conn := dial(t, rcon.SetRetryPolicy(policy), rcon.SetDeadline(50*time.Millisecond))
+		defer conn.Close()
+
+		if result, err := conn.Execute("slow"); err != nil || result != "slow" {
+			t.Errorf("got result %q and err %v, want %q and %v", result, err, "slow", nil)
+		}
+	})
+
+	t.Run("unsafe command", func(t *testing.T) {
+		conn := dial(t, rcon.SetRetryPolicy(policy))
+		defer conn.Close()
+
+		for _, command := range []string{"ban player", "give player"} {
+			attempts.Store(0)
+
+			if _, err := conn.Execute(command); !errors.Is(err, rcon.ErrInvalidPacketID) {
+				t.Errorf("got err %q, want %q", err, rcon.ErrInvalidPacketID)
+			}
+
+			if attempts.Load() != 1 {
+				t.Errorf("got %d attempts of %q, want 1", attempts.Load(), command)
+			}
+		}
+	})
+
+	t.Run("max attempts", func(t *testing.T) {
+		conn := dial(t, rcon.SetRetryPolicy(rcon.RetryPolicy{
+			MaxAttempts: 2,
+			MinBackoff:  time.Millisecond,
+			Safe:        rcon.MatchPrefix("status"),
+		}))
+		defer conn.Close()
+
+		if _, err := conn.Execute("status"); !errors.Is(err, rcon.ErrInvalidPacketID) {
+			t.Errorf("got err %q, want %q", err, rcon.ErrInvalidPacketID)
+		}
+
+		if attempts.Load() != 2 {
+			t.Errorf("got %d attempts, want 2", attempts.Load())
+		}
+	})
+
+	t.Run("no safe commands by default", func(t *testing.T) {
+		conn := dial(t, rcon.SetRetryPolicy(rcon.RetryPolicy{MaxAttempts: 3, MinBackoff: time.Millisecond}))
+		defer conn.Close()
+
+		if _, err := conn.Execute("status"); !errors.Is(err, rcon.ErrInvalidPacketID) {
+			t.Errorf("got err %q, want %q", err, rcon.ErrInvalidPacketID)
+		}
+
+		if attempts.Load() != 1 {
+			t.Errorf("got %d attempts, want 1", attempts.Load())
+		}
+	})
+
+	t.Run("client doesn't replay commands by retry policy", func(t *testing.T) {
+		attempts.Store(0)
+
+		client := rcon.NewClient(server.Addr(), "password",
+			rcon.SetConnOptions(rcon.SetRetryPolicy(policy)),
+			rcon.SetReconnectBackoff(time.Millisecond, time.Millisecond),
+		)
+		defer client.Close()
+
+		if _, err := client.Execute("crash"); err == nil {
+			t.Error("got nil err, want connection error")
+		}
+	})
+
+	t.Run("client replays safe commands", func(t *testing.T) {
+		attempts.Store(0)
+
+		client := rcon.NewClient(server.Addr(), "password",
+			rcon.SetConnOptions(rcon.SetRetryPolicy(policy)),
+			rcon.SetIdempotent(policy.IsSafe),
+			rcon.SetReconnectBackoff(time.Millisecond, time.Millisecond),
+		)
+		defer client.Close()
+
+		if result, err := client.Execute("crash"); err != nil || result != "crash" {
+			t.Errorf("got result %q and err %v, want %q and %v", result, err, "crash", nil)
+		}
+	})
+}
+
+func TestMatchPrefix(t *testing.T) {
+	match := rcon.MatchPrefix("ban", "kick")
+
+	for command, want := range map[string]bool{
+		"ban player": true,
+		"KICK":       true,
+		"ba":         false,
+		"status":     false,
+	} {
+		if got := match(command); got != want {
+			t.Errorf("got match %t for %q, want %t", got, command, want)
+		}
+	}
+}
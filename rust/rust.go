@@ -0,0 +1,295 @@
+// Package rust contains typed parsers of Rust RCON responses.
+package rust
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gorcon/rcon"
+)
+
+// ErrUnexpectedResponse is returned when the response is not the output of
+// the command, e.g. a console message assigned to the request.
+var ErrUnexpectedResponse = errors.New("unexpected response")
+
+// Dial creates a new authorized Conn with DialectRust, which skips the
+// undocumented packets with type 4 preceding the responses. Options are
+// applied after the dialect, so they may override it.
+func Dial(address string, password string, options ...rcon.Option) (*rcon.Conn, error) {
+	return DialContext(context.Background(), address, password, options...)
+}
+
+// DialContext is like Dial but uses the provided context like
+// rcon.DialContext does.
+func DialContext(ctx context.Context, address string, password string, options ...rcon.Option) (*rcon.Conn, error) {
+	options = append([]rcon.Option{rcon.SetDialect(rcon.DialectRust)}, options...)
+
+	return rcon.DialContext(ctx, address, password, options...)
+}
+
+// Executor executes console commands. It is implemented by *rcon.Conn and
+// *rcon.Client.
+type Executor interface {
+	ExecuteContext(ctx context.Context, command string) (string, error)
+}
+
+// Server executes Rust commands and parses their responses. The conn should
+// be created with Dial or rcon.DialectRust, otherwise the type 4 packets may
+// be returned instead of the responses.
+type Server struct {
+	conn Executor
+}
+
+// NewServer creates Server executing commands with conn.
+func NewServer(conn Executor) *Server {
+	return &Server{conn: conn}
+}
+
+// PlayerList executes playerlist command.
+func (s *Server) PlayerList(ctx context.Context) ([]Player, error) {
+	response, err := s.conn.ExecuteContext(ctx, "playerlist")
+	if err != nil {
+		return nil, err
+	}
+
+	return ParsePlayerList(response)
+}
+
+// ServerInfo executes serverinfo command.
+func (s *Server) ServerInfo(ctx context.Context) (*ServerInfo, error) {
+	response, err := s.conn.ExecuteContext(ctx, "serverinfo")
+	if err != nil {
+		return nil, err
+	}
+
+	return ParseServerInfo(response)
+}
+
+// Status executes status command.
+func (s *Server) Status(ctx context.Context) (*Status, error) {
+	response, err := s.conn.ExecuteContext(ctx, "status")
+	if err != nil {
+		return nil, err
+	}
+
+	return ParseStatus(response)
+}
+
+// Player is an element of playerlist response.
+type Player struct {
+	SteamID          string  `json:"SteamID"`
+	OwnerSteamID     string  `json:"OwnerSteamID"`
+	DisplayName      string  `json:"DisplayName"`
+	Ping             int     `json:"Ping"`
+	Address          string  `json:"Address"`
+	ConnectedSeconds int     `json:"ConnectedSeconds"`
+	ViolationLevel   float64 `json:"VoiationLevel"` // Misspelled by the server.
+	CurrentLevel     float64 `json:"CurrentLevel"`
+	UnspentXp        float64 `json:"UnspentXp"`
+	Health           float64 `json:"Health"`
+}
+
+// Connected returns the connection duration.
+func (p Player) Connected() time.Duration {
+	return time.Duration(p.ConnectedSeconds) * time.Second
+}
+
+// ServerInfo is serverinfo response.
+type ServerInfo struct {
+	Hostname          string  `json:"Hostname"`
+	MaxPlayers        int     `json:"MaxPlayers"`
+	Players           int     `json:"Players"`
+	Queued            int     `json:"Queued"`
+	Joining           int     `json:"Joining"`
+	ReservedSlots     int     `json:"ReservedSlots"`
+	EntityCount       int     `json:"EntityCount"`
+	GameTime          string  `json:"GameTime"`
+	Uptime            int     `json:"Uptime"`
+	Map               string  `json:"Map"`
+	Framerate         float64 `json:"Framerate"`
+	Memory            int     `json:"Memory"`
+	MemoryUsageSystem int     `json:"MemoryUsageSystem"`
+	Collections       int     `json:"Collections"`
+	NetworkIn         int     `json:"NetworkIn"`
+	NetworkOut        int     `json:"NetworkOut"`
+	Restarting        bool    `json:"Restarting"`
+	SaveCreatedTime   string  `json:"SaveCreatedTime"`
+	Version           int     `json:"Version"`
+	Protocol          string  `json:"Protocol"`
+}
+
+// ParsePlayerList parses playerlist response.
+func ParsePlayerList(s string) ([]Player, error) {
+	var players []Player
+	if err := decodeJSON(s, '[', &players); err != nil {
+		return nil, err
+	}
+
+	return players, nil
+}
+
+// ParseServerInfo parses serverinfo response.
+func ParseServerInfo(s string) (*ServerInfo, error) {
+	info := &ServerInfo{}
+	if err := decodeJSON(s, '{', info); err != nil {
+		return nil, err
+	}
+
+	return info, nil
+}
+
+// decodeJSON decodes the first JSON value starting with the open bracket.
+// Console messages logged around the value, which may be returned because
+// of the type 4 packet quirk, are skipped.
+func decodeJSON(s string, open byte, v any) error {
+	var err error
+
+	for offset := strings.IndexByte(s, open); offset >= 0; {
+		if err = json.NewDecoder(strings.NewReader(s[offset:])).Decode(v); err == nil {
+			return nil
+		}
+
+		next := strings.IndexByte(s[offset+1:], open)
+		if next < 0 {
+			break
+		}
+
+		offset += next + 1
+	}
+
+	if err != nil {
+		return fmt.Errorf("rust: %w: %w", ErrUnexpectedResponse, err)
+	}
+
+	return fmt.Errorf("rust: %w: %q", ErrUnexpectedResponse, s)
+}
+
+// Status is status response.
+type Status struct {
+	Hostname   string
+	Version    string
+	Map        string
+	Players    int
+	MaxPlayers int
+	Queued     int
+	Joining    int
+	Clients    []Client
+}
+
+// Client is a row of the status player list.
+type Client struct {
+	SteamID      string
+	Name         string
+	Ping         int
+	Connected    time.Duration
+	Address      string
+	OwnerSteamID string
+	Violation    float64
+	Kicks        int
+}
+
+// playersRegexp matches the players counts, e.g.
+// "2 (100 max) (0 queued) (0 joining)".
+var playersRegexp = regexp.MustCompile(`^(\d+) \((\d+) max\)(?: \((\d+) queued\))?(?: \((\d+) joining\))?`)
+
+// ParseStatus parses status response:
+//
+//	hostname: My Server
+//	version : 2510/241 secure (secure mode enabled, connected to Steam3)
+//	map     : Procedural Map
+//	players : 1 (100 max) (0 queued) (0 joining)
+//
+//	id                name    ping connected addr                 owner violation kicks
+//	76561198000000001 "Alice" 45   1234.5s   203.0.113.1:12345          0.0       0
+func ParseStatus(s string) (*Status, error) {
+	status := &Status{}
+	valid := false
+
+	scanner := bufio.NewScanner(strings.NewReader(s))
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+
+		if client, ok := parseClient(line); ok {
+			status.Clients = append(status.Clients, client)
+
+			continue
+		}
+
+		if key, value, ok := strings.Cut(line, ":"); ok {
+			valid = status.set(strings.TrimSpace(key), strings.TrimSpace(value)) || valid
+		}
+	}
+
+	if !valid {
+		return nil, fmt.Errorf("rust: %w: %q", ErrUnexpectedResponse, s)
+	}
+
+	return status, nil
+}
+
+// set sets the header field and reports whether the key is known.
+func (s *Status) set(key string, value string) bool {
+	switch key {
+	case "hostname":
+		s.Hostname = value
+	case "version":
+		s.Version = value
+	case "map":
+		s.Map = value
+	case "players":
+		match := playersRegexp.FindStringSubmatch(value)
+		if match == nil {
+			return false
+		}
+
+		s.Players, _ = strconv.Atoi(match[1])
+		s.MaxPlayers, _ = strconv.Atoi(match[2])
+		s.Queued, _ = strconv.Atoi(match[3])
+		s.Joining, _ = strconv.Atoi(match[4])
+	default:
+		return false
+	}
+
+	return true
+}
+
+// parseClient parses the row of the player list. The owner column is empty
+// unless the game is owned by another account with family sharing.
+func parseClient(line string) (Client, bool) {
+	start := strings.IndexByte(line, '"')
+	end := strings.LastIndexByte(line, '"')
+
+	if start < 0 || end == start {
+		return Client{}, false
+	}
+
+	steamID := strings.TrimSpace(line[:start])
+	if _, err := strconv.ParseUint(steamID, 10, 64); err != nil {
+		return Client{}, false
+	}
+
+	fields := strings.Fields(line[end+1:])
+	if len(fields) < 5 {
+		return Client{}, false
+	}
+
+	client := Client{SteamID: steamID, Name: line[start+1 : end], Address: fields[2]}
+	client.Ping, _ = strconv.Atoi(fields[0])
+	client.Connected, _ = time.ParseDuration(fields[1])
+
+	if len(fields) > 5 {
+		client.OwnerSteamID = fields[3]
+	}
+
+	client.Violation, _ = strconv.ParseFloat(fields[len(fields)-2], 64)
+	client.Kicks, _ = strconv.Atoi(fields[len(fields)-1])
+
+	return client, true
+}
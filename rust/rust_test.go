@@ -0,0 +1,167 @@
+package rust_test
+
+import (
+	"context"
+	"errors"
+	"os"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/gorcon/rcon"
+	"github.com/gorcon/rcon/rcontest"
+	"github.com/gorcon/rcon/rust"
+)
+
+// readFile returns the testdata file content.
+func readFile(t *testing.T, name string) string {
+	t.Helper()
+
+	data, err := os.ReadFile("testdata/" + name)
+	if err != nil {
+		t.Fatalf("got err %q, want %v", err, nil)
+	}
+
+	return string(data)
+}
+
+func TestParsePlayerList(t *testing.T) {
+	want := []rust.Player{
+		{
+			SteamID: "76561198000000001", OwnerSteamID: "0", DisplayName: "Alice", Ping: 45,
+			Address: "203.0.113.1:12345", ConnectedSeconds: 1234, Health: 87.5,
+		},
+		{
+			SteamID: "76561198000000002", OwnerSteamID: "76561198000000003", DisplayName: "Bob Smith", Ping: 80,
+			Address: "203.0.113.2:23456", ConnectedSeconds: 12, ViolationLevel: 1.5, Health: 100,
+		},
+	}
+
+	t.Run("json", func(t *testing.T) {
+		got, err := rust.ParsePlayerList(readFile(t, "playerlist.json"))
+		if err != nil {
+			t.Fatalf("got err %q, want %v", err, nil)
+		}
+
+		if !reflect.DeepEqual(got, want) {
+			t.Errorf("got %+v, want %+v", got, want)
+		}
+
+		if connected := got[0].Connected(); connected != 1234*time.Second {
+			t.Errorf("got connected %s, want %s", connected, 1234*time.Second)
+		}
+	})
+
+	t.Run("console messages around", func(t *testing.T) {
+		response := "[CHAT] Alice: hi\n" + readFile(t, "playerlist.json") + "Saved 1234 ents\n"
+
+		got, err := rust.ParsePlayerList(response)
+		if err != nil {
+			t.Fatalf("got err %q, want %v", err, nil)
+		}
+
+		if !reflect.DeepEqual(got, want) {
+			t.Errorf("got %+v, want %+v", got, want)
+		}
+	})
+
+	t.Run("unexpected", func(t *testing.T) {
+		if _, err := rust.ParsePlayerList("Command 'say' not found"); !errors.Is(err, rust.ErrUnexpectedResponse) {
+			t.Errorf("got err %q, want %q", err, rust.ErrUnexpectedResponse)
+		}
+	})
+}
+
+func TestParseServerInfo(t *testing.T) {
+	got, err := rust.ParseServerInfo(readFile(t, "serverinfo.json"))
+	if err != nil {
+		t.Fatalf("got err %q, want %v", err, nil)
+	}
+
+	want := &rust.ServerInfo{
+		Hostname: "My Rust Server", MaxPlayers: 100, Players: 2, Queued: 1, EntityCount: 123456,
+		GameTime: "06/15/2024 12:34:56", Uptime: 86400, Map: "Procedural Map", Framerate: 256,
+		Memory: 8123, MemoryUsageSystem: 16234, Collections: 42, NetworkIn: 12345, NetworkOut: 54321,
+		SaveCreatedTime: "06/14/2024 10:00:00", Version: 2510, Protocol: "2510.241.1",
+	}
+
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got %+v, want %+v", got, want)
+	}
+}
+
+func TestParseStatus(t *testing.T) {
+	got, err := rust.ParseStatus(readFile(t, "status.txt"))
+	if err != nil {
+		t.Fatalf("got err %q, want %v", err, nil)
+	}
+
+	want := &rust.Status{
+		Hostname: "My Rust Server",
+		Version:  "2510/241 secure (secure mode enabled, connected to Steam3)",
+		Map:      "Procedural Map", Players: 2, MaxPlayers: 100, Queued: 1,
+		Clients: []rust.Client{
+			{
+				SteamID: "76561198000000001", Name: "Alice", Ping: 45, Connected: 1234500 * time.Millisecond,
+				Address: "203.0.113.1:12345",
+			},
+			{
+				SteamID: "76561198000000002", Name: "Bob Smith", Ping: 80, Connected: 12300 * time.Millisecond,
+				Address: "203.0.113.2:23456", OwnerSteamID: "76561198000000003", Violation: 1.5, Kicks: 2,
+			},
+		},
+	}
+
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got %+v, want %+v", got, want)
+	}
+
+	if _, err := rust.ParseStatus("Unknown command"); !errors.Is(err, rust.ErrUnexpectedResponse) {
+		t.Errorf("got err %q, want %q", err, rust.ErrUnexpectedResponse)
+	}
+}
+
+func TestServer(t *testing.T) {
+	playerList := readFile(t, "playerlist.json")
+	serverInfo := readFile(t, "serverinfo.json")
+	status := readFile(t, "status.txt")
+
+	server := rcontest.NewServer(
+		rcontest.SetSettings(rcontest.Settings{Password: "password"}),
+		rcontest.SetCommandHandler(func(c *rcontest.Context) {
+			// Rust precedes responses with the undocumented type 4 packet.
+			rcon.NewPacket(4, c.Request().ID, "").WriteTo(c.Conn())
+
+			switch c.Request().Body() {
+			case "playerlist":
+				c.WriteResponse(playerList)
+			case "serverinfo":
+				c.WriteResponse(serverInfo)
+			case "status":
+				c.WriteResponse(status)
+			}
+		}),
+	)
+	defer server.Close()
+
+	conn, err := rust.Dial(server.Addr(), "password")
+	if err != nil {
+		t.Fatalf("got err %q, want %v", err, nil)
+	}
+	defer conn.Close()
+
+	rustServer := rust.NewServer(conn)
+	ctx := context.Background()
+
+	if players, err := rustServer.PlayerList(ctx); err != nil || len(players) != 2 {
+		t.Errorf("got %d players, err %v, want %d, %v", len(players), err, 2, nil)
+	}
+
+	if info, err := rustServer.ServerInfo(ctx); err != nil || info.Hostname != "My Rust Server" {
+		t.Errorf("got %+v, err %v, want hostname %q", info, err, "My Rust Server")
+	}
+
+	if status, err := rustServer.Status(ctx); err != nil || len(status.Clients) != 2 {
+		t.Errorf("got %+v, err %v, want %d clients", status, err, 2)
+	}
+}
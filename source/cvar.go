@@ -0,0 +1,293 @@
+package source
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+var (
+	// ErrUnknownCvar is returned when the server doesn't know the cvar.
+	ErrUnknownCvar = errors.New("unknown cvar")
+
+	// ErrInvalidCvar is returned when the response is not cvar output or
+	// the cvar name or value can not be sent to the server.
+	ErrInvalidCvar = errors.New("invalid cvar")
+)
+
+// Executor executes console commands. It is implemented by *rcon.Conn and
+// *rcon.Client.
+type Executor interface {
+	ExecuteContext(ctx context.Context, command string) (string, error)
+}
+
+// Cvar is a console variable.
+type Cvar struct {
+	Name        string
+	Value       string
+	Default     string
+	Flags       []string
+	Description string
+}
+
+// Int returns the value as int.
+func (c Cvar) Int() (int, error) {
+	value, err := strconv.Atoi(c.Value)
+	if err != nil {
+		return 0, fmt.Errorf("source: %w", err)
+	}
+
+	return value, nil
+}
+
+// Float returns the value as float64.
+func (c Cvar) Float() (float64, error) {
+	value, err := strconv.ParseFloat(c.Value, 64)
+	if err != nil {
+		return 0, fmt.Errorf("source: %w", err)
+	}
+
+	return value, nil
+}
+
+// Bool returns the value as bool. Numbers are true if not zero like the
+// engine treats them.
+func (c Cvar) Bool() (bool, error) {
+	if value, err := strconv.ParseFloat(c.Value, 64); err == nil {
+		return value != 0, nil
+	}
+
+	value, err := strconv.ParseBool(c.Value)
+	if err != nil {
+		return false, fmt.Errorf("source: %w", err)
+	}
+
+	return value, nil
+}
+
+// Cvars gets and sets console variables over RCON.
+type Cvars struct {
+	conn Executor
+}
+
+// NewCvars creates Cvars executing commands with conn.
+func NewCvars(conn Executor) *Cvars {
+	return &Cvars{conn: conn}
+}
+
+// Get returns the cvar. The response to another cvar, e.g. a console
+// message, fails with ErrInvalidCvar.
+func (c *Cvars) Get(ctx context.Context, name string) (Cvar, error) {
+	if !validCvarName(name) {
+		return Cvar{}, fmt.Errorf("source: %w: name %q", ErrInvalidCvar, name)
+	}
+
+	response, err := c.conn.ExecuteContext(ctx, name)
+	if err != nil {
+		return Cvar{}, err
+	}
+
+	cvar, err := ParseCvar(response)
+	if err != nil {
+		return Cvar{}, err
+	}
+
+	// Cvar names are case-insensitive.
+	if !strings.EqualFold(cvar.Name, name) {
+		return Cvar{}, fmt.Errorf("source: %w: got %q for %q", ErrInvalidCvar, cvar.Name, name)
+	}
+
+	return cvar, nil
+}
+
+// GetInt returns the cvar value as int.
+func (c *Cvars) GetInt(ctx context.Context, name string) (int, error) {
+	cvar, err := c.Get(ctx, name)
+	if err != nil {
+		return 0, err
+	}
+
+	return cvar.Int()
+}
+
+// GetFloat returns the cvar value as float64.
+func (c *Cvars) GetFloat(ctx context.Context, name string) (float64, error) {
+	cvar, err := c.Get(ctx, name)
+	if err != nil {
+		return 0, err
+	}
+
+	return cvar.Float()
+}
+
+// GetBool returns the cvar value as bool.
+func (c *Cvars) GetBool(ctx context.Context, name string) (bool, error) {
+	cvar, err := c.Get(ctx, name)
+	if err != nil {
+		return false, err
+	}
+
+	return cvar.Bool()
+}
+
+// Set sets the cvar value. The value is quoted, so it may contain spaces and
+// semicolons, but not double quotes and line breaks, which can not be
+// escaped in the console.
+func (c *Cvars) Set(ctx context.Context, name string, value string) error {
+	if !validCvarName(name) {
+		return fmt.Errorf("source: %w: name %q", ErrInvalidCvar, name)
+	}
+
+	if strings.ContainsAny(value, "\"\r\n") {
+		return fmt.Errorf("source: %w: value %q", ErrInvalidCvar, value)
+	}
+
+	response, err := c.conn.ExecuteContext(ctx, name+` "`+value+`"`)
+	if err != nil {
+		return err
+	}
+
+	if isUnknownCommand(response) {
+		return fmt.Errorf("source: %w: %s", ErrUnknownCvar, name)
+	}
+
+	return nil
+}
+
+// validCvarName reports whether name can be sent as a single command token.
+func validCvarName(name string) bool {
+	return name != "" && !strings.ContainsAny(name, " \t\r\n\";")
+}
+
+// isUnknownCommand reports whether the response is the unknown command
+// error.
+func isUnknownCommand(response string) bool {
+	return strings.HasPrefix(strings.TrimSpace(response), "Unknown command")
+}
+
+var (
+	// cvarRegexp matches the first line of cvar output, e.g.
+	// "sv_cheats" = "0" ( def. "0" ) notify replicated.
+	cvarRegexp = regexp.MustCompile(`^"([^"]+)" = "([^"]*)"(?: \( def\. "([^"]*)" \))?(.*)$`)
+
+	// cvar2Regexp matches the first line of CS2 cvar output, e.g.
+	// sv_cheats = false. The name is an identifier, so console messages
+	// containing " = " don't match.
+	cvar2Regexp = regexp.MustCompile(`^([A-Za-z_][A-Za-z0-9_.]*) = (.*)$`)
+
+	// boundsRegexp matches the min and max values of the cvar.
+	boundsRegexp = regexp.MustCompile(`\b(?:min|max)\. \S+`)
+)
+
+// ParseCvar parses the response to the cvar name command:
+//
+//	"sv_cheats" = "0" ( def. "0" )
+//	 notify replicated
+//	 - Allow cheats on server
+//
+// Flags may follow the values on the first line, the default is omitted by
+// some games if it equals the value.
+func ParseCvar(s string) (Cvar, error) {
+	scanner := bufio.NewScanner(strings.NewReader(s))
+
+	var first string
+
+	for first == "" && scanner.Scan() {
+		first = strings.TrimSpace(scanner.Text())
+	}
+
+	var (
+		cvar  Cvar
+		flags string
+	)
+
+	if isUnknownCommand(first) {
+		return Cvar{}, fmt.Errorf("source: %w: %s", ErrUnknownCvar, first)
+	}
+
+	if match := cvarRegexp.FindStringSubmatch(first); match != nil {
+		cvar = Cvar{Name: match[1], Value: match[2], Default: match[3]}
+		flags = match[4]
+	} else if match := cvar2Regexp.FindStringSubmatch(first); match != nil {
+		cvar = Cvar{Name: match[1], Value: strings.TrimSpace(match[2])}
+	} else {
+		return Cvar{}, fmt.Errorf("source: %w: %q", ErrInvalidCvar, first)
+	}
+
+	var description []string
+
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+
+		if text, ok := strings.CutPrefix(line, "- "); ok {
+			description = append(description, text)
+		} else if line != "" {
+			flags += " " + line
+		}
+	}
+
+	if fields := strings.Fields(boundsRegexp.ReplaceAllString(flags, "")); len(fields) > 0 {
+		cvar.Flags = fields
+	}
+
+	cvar.Description = strings.Join(description, "\n")
+
+	return cvar, nil
+}
+
+// ParseCvarList parses the output of cvarlist command:
+//
+//	sv_cheats                                : 0        : , "nf", "rep"    : Allow cheats on server
+//	status                                   : cmd      :                  : Display map and connection status.
+//
+// Console commands with cmd value are skipped. Default values are not
+// printed by cvarlist, so Default is left empty.
+func ParseCvarList(s string) ([]Cvar, error) {
+	var cvars []Cvar
+
+	scanner := bufio.NewScanner(strings.NewReader(s))
+	for scanner.Scan() {
+		columns := strings.SplitN(scanner.Text(), " : ", 4)
+		if len(columns) < 3 {
+			continue
+		}
+
+		name := strings.TrimSpace(columns[0])
+		value := strings.TrimSpace(columns[1])
+
+		if name == "" || value == "cmd" {
+			continue
+		}
+
+		cvar := Cvar{Name: name, Value: value, Flags: parseFlags(columns[2])}
+		if len(columns) == 4 {
+			cvar.Description = strings.TrimSpace(columns[3])
+		}
+
+		cvars = append(cvars, cvar)
+	}
+
+	if len(cvars) == 0 {
+		return nil, fmt.Errorf("source: %w: no cvars in cvarlist output", ErrInvalidCvar)
+	}
+
+	return cvars, nil
+}
+
+// parseFlags parses comma separated and optionally quoted flags, e.g.
+// , "sv", "cheat".
+func parseFlags(s string) []string {
+	var flags []string
+
+	for _, flag := range strings.Split(s, ",") {
+		if flag = strings.Trim(strings.TrimSpace(flag), `"`); flag != "" {
+			flags = append(flags, flag)
+		}
+	}
+
+	return flags
+}
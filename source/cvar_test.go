@@ -0,0 +1,170 @@
+package source_test
+
+import (
+	"context"
+	"errors"
+	"os"
+	"reflect"
+	"testing"
+
+	"github.com/gorcon/rcon/source"
+)
+
+// executor is source.Executor which answers with responses by command.
+type executor struct {
+	responses map[string]string
+	commands  []string
+}
+
+func (e *executor) ExecuteContext(_ context.Context, command string) (string, error) {
+	e.commands = append(e.commands, command)
+
+	if response, ok := e.responses[command]; ok {
+		return response, nil
+	}
+
+	return "Unknown command \"" + command + "\"\n", nil
+}
+
+func TestParseCvar(t *testing.T) {
+	tests := []struct {
+		name     string
+		response string
+		want     source.Cvar
+	}{
+		{"tf2", "\"sv_cheats\" = \"0\" ( def. \"0\" )\n notify replicated\n - Allow cheats on server\n", source.Cvar{
+			Name: "sv_cheats", Value: "0", Default: "0",
+			Flags: []string{"notify", "replicated"}, Description: "Allow cheats on server",
+		}},
+		{"csgo", "\"fps_max\" = \"300\" ( def. \"300\" ) min. 0.000000 max. 999.000000 archive\n - Frame rate limiter\n",
+			source.Cvar{
+				Name: "fps_max", Value: "300", Default: "300",
+				Flags: []string{"archive"}, Description: "Frame rate limiter",
+			}},
+		{"default omitted", "\"hostname\" = \"My Server\"\n - Hostname for server.\n", source.Cvar{
+			Name: "hostname", Value: "My Server", Description: "Hostname for server.",
+		}},
+		{"cs2", "sv_cheats = false\n", source.Cvar{Name: "sv_cheats", Value: "false"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := source.ParseCvar(tt.response)
+			if err != nil {
+				t.Fatalf("got err %q, want %v", err, nil)
+			}
+
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("got %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+
+	t.Run("unknown", func(t *testing.T) {
+		if _, err := source.ParseCvar(`Unknown command "sv_foo"`); !errors.Is(err, source.ErrUnknownCvar) {
+			t.Errorf("got err %q, want %q", err, source.ErrUnknownCvar)
+		}
+	})
+
+	t.Run("invalid", func(t *testing.T) {
+		for _, response := range []string{
+			"L 01/01/2024 - 00:00:00: rcon from ...",
+			"[SM] = Plugin loaded.",
+			"\"sv_cheats\" = 1",
+		} {
+			if _, err := source.ParseCvar(response); !errors.Is(err, source.ErrInvalidCvar) {
+				t.Errorf("got err %v for %q, want %q", err, response, source.ErrInvalidCvar)
+			}
+		}
+	})
+}
+
+func TestCvars(t *testing.T) {
+	conn := &executor{responses: map[string]string{
+		"sv_cheats":             "\"sv_cheats\" = \"1\" ( def. \"0\" )\n notify replicated\n",
+		"sv_gravity":            "\"sv_gravity\" = \"800.5\" ( def. \"800\" )\n",
+		"mp_maxrounds":          "\"mp_maxrounds\" = \"30\" ( def. \"0\" )\n",
+		"mp_timelimit":          "\"mp_roundtime\" = \"5\" ( def. \"5\" )\n",
+		"SV_Cheats":             "\"sv_cheats\" = \"1\" ( def. \"0\" )\n",
+		`hostname "My; Server"`: "",
+	}}
+	cvars := source.NewCvars(conn)
+	ctx := context.Background()
+
+	if got, err := cvars.GetBool(ctx, "sv_cheats"); err != nil || !got {
+		t.Errorf("got %v, %v, want %v, %v", got, err, true, nil)
+	}
+
+	if got, err := cvars.GetFloat(ctx, "sv_gravity"); err != nil || got != 800.5 {
+		t.Errorf("got %v, %v, want %v, %v", got, err, 800.5, nil)
+	}
+
+	if got, err := cvars.GetInt(ctx, "mp_maxrounds"); err != nil || got != 30 {
+		t.Errorf("got %v, %v, want %v, %v", got, err, 30, nil)
+	}
+
+	if _, err := cvars.GetInt(ctx, "sv_gravity"); err == nil {
+		t.Errorf("got err %v, want error", err)
+	}
+
+	if _, err := cvars.Get(ctx, "mp_timelimit"); !errors.Is(err, source.ErrInvalidCvar) {
+		t.Errorf("got err %v, want %q", err, source.ErrInvalidCvar)
+	}
+
+	if _, err := cvars.Get(ctx, "SV_Cheats"); err != nil {
+		t.Errorf("got err %q, want %v", err, nil)
+	}
+
+	if err := cvars.Set(ctx, "hostname", "My; Server"); err != nil {
+		t.Errorf("got err %q, want %v", err, nil)
+	}
+
+	if err := cvars.Set(ctx, "sv_foo", "1"); !errors.Is(err, source.ErrUnknownCvar) {
+		t.Errorf("got err %q, want %q", err, source.ErrUnknownCvar)
+	}
+
+	if err := cvars.Set(ctx, "hostname", `"; quit`); !errors.Is(err, source.ErrInvalidCvar) {
+		t.Errorf("got err %q, want %q", err, source.ErrInvalidCvar)
+	}
+
+	if _, err := cvars.Get(ctx, "sv_cheats; quit"); !errors.Is(err, source.ErrInvalidCvar) {
+		t.Errorf("got err %q, want %q", err, source.ErrInvalidCvar)
+	}
+
+	want := []string{
+		"sv_cheats", "sv_gravity", "mp_maxrounds", "sv_gravity", "mp_timelimit", "SV_Cheats",
+		`hostname "My; Server"`, `sv_foo "1"`,
+	}
+	if !reflect.DeepEqual(conn.commands, want) {
+		t.Errorf("got commands %q, want %q", conn.commands, want)
+	}
+}
+
+func TestParseCvarList(t *testing.T) {
+	input, err := os.ReadFile("testdata/cvarlist.txt")
+	if err != nil {
+		t.Fatalf("got err %q, want %v", err, nil)
+	}
+
+	got, err := source.ParseCvarList(string(input))
+	if err != nil {
+		t.Fatalf("got err %q, want %v", err, nil)
+	}
+
+	want := []source.Cvar{
+		{Name: "mp_timelimit", Value: "30", Flags: []string{"nf", "rep"}, Description: "game time per map in minutes"},
+		{Name: "sv_cheats", Value: "0", Flags: []string{"nf", "rep"}, Description: "Allow cheats on server"},
+		{
+			Name: "sv_password", Flags: []string{"nf", "prot", "norecord"},
+			Description: "Server password for entry into multiplayer games",
+		},
+	}
+
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got %+v, want %+v", got, want)
+	}
+
+	if _, err := source.ParseCvarList("Unknown command \"cvarlist\""); !errors.Is(err, source.ErrInvalidCvar) {
+		t.Errorf("got err %q, want %q", err, source.ErrInvalidCvar)
+	}
+}
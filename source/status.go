@@ -0,0 +1,274 @@
+// Package source contains parsers of Source engine RCON responses.
+package source
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ErrInvalidStatus is returned when the response is not status command
+// output.
+var ErrInvalidStatus = errors.New("invalid status output")
+
+// cs2Bot is the user ID of the placeholder client listed by CS2.
+const cs2Bot = 65535
+
+// Status is the parsed output of status command.
+type Status struct {
+	Hostname string
+	Version  string
+	Address  string
+	OS       string
+	Map      string
+
+	Humans     int
+	Bots       int
+	MaxPlayers int
+
+	Players []Player
+}
+
+// Player is a row of the player list. Fields missing in the game format
+// are left zero.
+type Player struct {
+	UserID    int
+	Name      string
+	SteamID   string
+	Connected time.Duration
+	Ping      int
+	Loss      int
+	State     string
+	Rate      int
+	Address   string
+	Bot       bool
+}
+
+var (
+	// playersRegexp matches the players counts, e.g.
+	// "2 humans, 1 bots (12/0 max)" or "2 (24 max)".
+	playersRegexp = regexp.MustCompile(`^(\d+)(?: humans?, (\d+) bots?)? \((\d+)(?:/\d+)? max\)`)
+
+	// spawnGroupRegexp matches CS2 map spawn group, e.g.
+	// "SV:  [1: de_dust2 | main lump | mapload]".
+	spawnGroupRegexp = regexp.MustCompile(`\[\d+: ([^ |\]]+)`)
+)
+
+// ParseStatus parses the output of status command of CS:GO, CS2, TF2, L4D2
+// and other Source engine games. Unknown lines are ignored.
+func ParseStatus(s string) (*Status, error) {
+	status := &Status{}
+	valid := false
+	cs2Players := false
+
+	scanner := bufio.NewScanner(strings.NewReader(s))
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+
+		switch {
+		case line == "" || line == "#end":
+		case strings.HasPrefix(line, "---------players"):
+			cs2Players = true
+		case strings.HasPrefix(line, "# userid"), strings.HasPrefix(line, "id "):
+			// Header of the player list.
+		case strings.HasPrefix(line, "#"):
+			if player, ok := parsePlayer(line[1:]); ok {
+				status.Players = append(status.Players, player)
+			}
+		case cs2Players:
+			if player, ok := parseCS2Player(line); ok && player.UserID != cs2Bot {
+				status.Players = append(status.Players, player)
+			}
+		default:
+			if key, value, ok := strings.Cut(line, ":"); ok {
+				valid = status.set(strings.TrimSpace(key), strings.TrimSpace(value)) || valid
+			}
+		}
+	}
+
+	if !valid {
+		return nil, fmt.Errorf("source: %w", ErrInvalidStatus)
+	}
+
+	return status, nil
+}
+
+// set sets the header field and reports whether the key is known.
+func (s *Status) set(key string, value string) bool {
+	switch {
+	case key == "hostname":
+		s.Hostname = value
+	case key == "version":
+		s.Version = value
+	case key == "udp/ip":
+		s.Address = firstField(value)
+	case key == "os" || key == "os/type":
+		s.OS = value
+	case key == "map":
+		s.Map = firstField(value)
+	case key == "players":
+		match := playersRegexp.FindStringSubmatch(value)
+		if match == nil {
+			return false
+		}
+
+		s.Humans, _ = strconv.Atoi(match[1])
+		s.Bots, _ = strconv.Atoi(match[2])
+		s.MaxPlayers, _ = strconv.Atoi(match[3])
+	case strings.HasPrefix(key, "loaded spawngroup"):
+		// CS2 lists the map as the first spawn group.
+		if match := spawnGroupRegexp.FindStringSubmatch(value); match != nil && s.Map == "" {
+			s.Map = match[1]
+		}
+	default:
+		return false
+	}
+
+	return true
+}
+
+// parsePlayer parses the player row of CS:GO, TF2 and L4D2 format without
+// the leading #:
+//
+//	2 1 "Name" STEAM_1:0:12345 05:12 45 0 active 196608 1.2.3.4:27005
+//	2 "Name" [U:1:12345] 1:05:12 45 0 active 1.2.3.4:27005
+//	3 "Name" BOT active 64
+//
+// CS:GO and L4D2 print the slot after the user ID, TF2 doesn't print the
+// rate.
+func parsePlayer(line string) (Player, bool) {
+	start := strings.IndexByte(line, '"')
+	end := strings.LastIndexByte(line, '"')
+
+	if start < 0 || end == start {
+		return Player{}, false
+	}
+
+	ids := strings.Fields(line[:start])
+	if len(ids) == 0 {
+		return Player{}, false
+	}
+
+	userID, err := strconv.Atoi(ids[0])
+	if err != nil {
+		return Player{}, false
+	}
+
+	player := Player{UserID: userID, Name: line[start+1 : end]}
+	fields := strings.Fields(line[end+1:])
+
+	if len(fields) > 0 && fields[0] == "BOT" {
+		player.Bot = true
+		fields = fields[1:]
+
+		if len(fields) > 0 {
+			player.State, fields = fields[0], fields[1:]
+		}
+
+		player.Rate, player.Address = parseRateAddress(fields)
+
+		return player, true
+	}
+
+	if len(fields) < 5 {
+		return Player{}, false
+	}
+
+	player.SteamID = fields[0]
+	player.Connected = parseConnected(fields[1])
+	player.Ping, _ = strconv.Atoi(fields[2])
+	player.Loss, _ = strconv.Atoi(fields[3])
+	player.State = fields[4]
+	player.Rate, player.Address = parseRateAddress(fields[5:])
+
+	return player, true
+}
+
+// parseCS2Player parses the player row of CS2 format:
+//
+//	2    01:23   39    0     active 786432 1.2.3.4:27005 'Name'
+//	3      BOT    0    0     active      0 'Name'
+func parseCS2Player(line string) (Player, bool) {
+	start := strings.IndexByte(line, '\'')
+	end := strings.LastIndexByte(line, '\'')
+
+	if start < 0 || end == start {
+		return Player{}, false
+	}
+
+	fields := strings.Fields(line[:start])
+	if len(fields) < 5 {
+		return Player{}, false
+	}
+
+	userID, err := strconv.Atoi(fields[0])
+	if err != nil {
+		return Player{}, false
+	}
+
+	player := Player{UserID: userID, Name: line[start+1 : end]}
+
+	if fields[1] == "BOT" {
+		player.Bot = true
+	} else {
+		player.Connected = parseConnected(fields[1])
+	}
+
+	player.Ping, _ = strconv.Atoi(fields[2])
+	player.Loss, _ = strconv.Atoi(fields[3])
+	player.State = fields[4]
+	player.Rate, player.Address = parseRateAddress(fields[5:])
+
+	return player, true
+}
+
+// parseRateAddress parses the optional rate and address columns. Wide rate
+// may be glued to the address, e.g. "0unknown".
+func parseRateAddress(fields []string) (int, string) {
+	switch len(fields) {
+	case 0:
+		return 0, ""
+	case 1:
+		digits := len(fields[0]) - len(strings.TrimLeft(fields[0], "0123456789"))
+		if strings.Contains(fields[0], ":") || digits == 0 {
+			return 0, fields[0]
+		}
+
+		rate, _ := strconv.Atoi(fields[0][:digits])
+
+		return rate, fields[0][digits:]
+	default:
+		rate, _ := strconv.Atoi(fields[0])
+
+		return rate, fields[1]
+	}
+}
+
+// parseConnected parses the connection time in [hh:]mm:ss format.
+func parseConnected(s string) time.Duration {
+	var total time.Duration
+
+	for _, part := range strings.Split(s, ":") {
+		n, err := strconv.Atoi(part)
+		if err != nil {
+			return 0
+		}
+
+		total = total*60 + time.Duration(n)
+	}
+
+	return total * time.Second
+}
+
+// firstField returns the first space separated field of s.
+func firstField(s string) string {
+	if fields := strings.Fields(s); len(fields) > 0 {
+		return fields[0]
+	}
+
+	return ""
+}
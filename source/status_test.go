@@ -0,0 +1,64 @@
+package source_test
+
+import (
+	"encoding/json"
+	"errors"
+	"flag"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/gorcon/rcon/source"
+)
+
+var update = flag.Bool("update", false, "update golden files")
+
+func TestParseStatus(t *testing.T) {
+	files, err := filepath.Glob("testdata/status/*.txt")
+	if err != nil {
+		t.Fatalf("got err %q, want %v", err, nil)
+	}
+
+	for _, file := range files {
+		t.Run(filepath.Base(file), func(t *testing.T) {
+			input, err := os.ReadFile(file)
+			if err != nil {
+				t.Fatalf("got err %q, want %v", err, nil)
+			}
+
+			status, err := source.ParseStatus(string(input))
+			if err != nil {
+				t.Fatalf("got err %q, want %v", err, nil)
+			}
+
+			got, err := json.MarshalIndent(status, "", "\t")
+			if err != nil {
+				t.Fatalf("got err %q, want %v", err, nil)
+			}
+
+			golden := strings.TrimSuffix(file, ".txt") + ".golden"
+
+			if *update {
+				if err := os.WriteFile(golden, append(got, '\n'), 0o600); err != nil {
+					t.Fatalf("got err %q, want %v", err, nil)
+				}
+			}
+
+			want, err := os.ReadFile(golden)
+			if err != nil {
+				t.Fatalf("got err %q, want %v", err, nil)
+			}
+
+			if string(got)+"\n" != string(want) {
+				t.Errorf("got status\n%s\nwant\n%s", got, want)
+			}
+		})
+	}
+}
+
+func TestParseStatus_Invalid(t *testing.T) {
+	if _, err := source.ParseStatus("Unknown command \"status\""); !errors.Is(err, source.ErrInvalidStatus) {
+		t.Errorf("got err %q, want %q", err, source.ErrInvalidStatus)
+	}
+}
@@ -0,0 +1,132 @@
+package rcon
+
+import (
+	"slices"
+	"sync/atomic"
+)
+
+// PacketFilter selects unsolicited packets delivered to Subscription.
+type PacketFilter struct {
+	// Types are the accepted packet types. Any type is accepted if empty.
+	Types []int32
+
+	// IDs are the accepted packet IDs. Any ID is accepted if empty.
+	IDs []int32
+}
+
+// Match reports whether the packet satisfies the filter.
+func (f PacketFilter) Match(packet *Packet) bool {
+	if len(f.Types) > 0 && !slices.Contains(f.Types, packet.Type) {
+		return false
+	}
+
+	if len(f.IDs) > 0 && !slices.Contains(f.IDs, packet.ID) {
+		return false
+	}
+
+	return true
+}
+
+// Subscription delivers unsolicited packets, such as console output and
+// chat messages pushed by the server, which don't belong to any request.
+type Subscription struct {
+	conn    *Conn
+	filter  PacketFilter
+	packets chan *Packet
+	handler func(packet *Packet)
+	dropped atomic.Int64
+	closed  bool
+}
+
+// Subscribe creates a Subscription which delivers unsolicited packets
+// matching filter to the channel returned by C. The channel has buffer
+// capacity, packets are dropped when it is full. The channel is closed on
+// Unsubscribe or when Conn is closed.
+func (c *Conn) Subscribe(filter PacketFilter, buffer int) *Subscription {
+	return c.subscribe(&Subscription{conn: c, filter: filter, packets: make(chan *Packet, buffer)})
+}
+
+// SubscribeFunc creates a Subscription which calls handler for each
+// unsolicited packet matching filter. The handler is called from the Conn
+// reader goroutine, so it must not block.
+func (c *Conn) SubscribeFunc(filter PacketFilter, handler func(packet *Packet)) *Subscription {
+	return c.subscribe(&Subscription{conn: c, filter: filter, handler: handler})
+}
+
+// C returns the channel which receives packets. It is nil for Subscription
+// created by SubscribeFunc.
+func (s *Subscription) C() <-chan *Packet {
+	return s.packets
+}
+
+// Dropped returns the number of packets dropped because the channel was full.
+func (s *Subscription) Dropped() int64 {
+	return s.dropped.Load()
+}
+
+// Unsubscribe stops delivering packets and closes the channel.
+func (s *Subscription) Unsubscribe() {
+	s.conn.mu.Lock()
+	defer s.conn.mu.Unlock()
+
+	s.conn.subscriptions = slices.DeleteFunc(s.conn.subscriptions, func(sub *Subscription) bool {
+		return sub == s
+	})
+
+	s.close()
+}
+
+// close closes the channel once. c.mu must be held.
+func (s *Subscription) close() {
+	if !s.closed && s.packets != nil {
+		close(s.packets)
+	}
+
+	s.closed = true
+}
+
+// deliver passes the packet to the subscriber. c.mu must be held for
+// channel subscriptions.
+func (s *Subscription) deliver(packet *Packet) {
+	if s.handler != nil {
+		s.handler(packet)
+
+		return
+	}
+
+	select {
+	case s.packets <- packet:
+	default:
+		s.dropped.Add(1)
+	}
+}
+
+// subscribe adds the subscription to Conn. The subscription is closed
+// right away if Conn has been already stopped.
+func (c *Conn) subscribe(s *Subscription) *Subscription {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.err != nil {
+		s.close()
+
+		return s
+	}
+
+	c.subscriptions = append(c.subscriptions, s)
+
+	return s
+}
+
+// subscribers returns subscriptions matching the packet. c.mu must be held.
+func (c *Conn) subscribers(packet *Packet) []*Subscription {
+	var subs []*Subscription
+
+	for _, s := range c.subscriptions {
+		if s.filter.Match(packet) {
+			subs = append(subs, s)
+		}
+	}
+
+	return subs
+}
@@ -0,0 +1,181 @@
+package rcon_test
+
+import (
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/gorcon/rcon"
+	"github.com/gorcon/rcon/rcontest"
+)
+
+func consoleHandler(c *rcontest.Context) {
+	// Unsolicited console message is pushed before the command response.
+	rcon.NewPacket(rcon.SERVERDATA_RESPONSE_VALUE, -1, "console message").WriteTo(c.Conn())
+	rcon.NewPacket(1, 0, "chat message").WriteTo(c.Conn())
+
+	rcon.NewPacket(rcon.SERVERDATA_RESPONSE_VALUE, c.Request().ID, c.Request().Body()).WriteTo(c.Conn())
+}
+
+func TestConn_Subscribe(t *testing.T) {
+	server := rcontest.NewServer(
+		rcontest.SetSettings(rcontest.Settings{Password: "password"}),
+		rcontest.SetCommandHandler(consoleHandler),
+	)
+	defer server.Close()
+
+	t.Run("channel", func(t *testing.T) {
+		conn, err := rcon.Dial(server.Addr(), "password")
+		if err != nil {
+			t.Fatalf("got err %q, want %v", err, nil)
+		}
+		defer conn.Close()
+
+		sub := conn.Subscribe(rcon.PacketFilter{}, 10)
+		defer sub.Unsubscribe()
+
+		result, err := conn.Execute("say")
+		if err != nil {
+			t.Fatalf("got err %q, want %v", err, nil)
+		}
+
+		if result != "say" {
+			t.Fatalf("got result %q, want %q", result, "say")
+		}
+
+		for _, want := range []string{"console message", "chat message"} {
+			select {
+			case packet := <-sub.C():
+				if packet.Body() != want {
+					t.Errorf("got packet body %q, want %q", packet.Body(), want)
+				}
+			case <-time.After(time.Second):
+				t.Fatalf("got no packet, want %q", want)
+			}
+		}
+	})
+
+	t.Run("filter", func(t *testing.T) {
+		conn, err := rcon.Dial(server.Addr(), "password")
+		if err != nil {
+			t.Fatalf("got err %q, want %v", err, nil)
+		}
+		defer conn.Close()
+
+		var (
+			mu     sync.Mutex
+			bodies []string
+		)
+
+		sub := conn.SubscribeFunc(rcon.PacketFilter{Types: []int32{1}}, func(packet *rcon.Packet) {
+			mu.Lock()
+			bodies = append(bodies, packet.Body())
+			mu.Unlock()
+		})
+		defer sub.Unsubscribe()
+
+		// Console message doesn't match the filter and is dropped.
+		result, err := conn.Execute("say")
+		if err != nil {
+			t.Fatalf("got err %q, want %v", err, nil)
+		}
+
+		if result != "say" {
+			t.Fatalf("got result %q, want %q", result, "say")
+		}
+
+		time.Sleep(100 * time.Millisecond)
+
+		mu.Lock()
+		defer mu.Unlock()
+
+		if len(bodies) != 1 || bodies[0] != "chat message" {
+			t.Errorf("got packets %q, want %q", bodies, []string{"chat message"})
+		}
+	})
+
+	t.Run("no subscriptions", func(t *testing.T) {
+		conn, err := rcon.Dial(server.Addr(), "password")
+		if err != nil {
+			t.Fatalf("got err %q, want %v", err, nil)
+		}
+		defer conn.Close()
+
+		// Unsolicited packets are dropped and don't fail the command.
+		result, err := conn.Execute("say")
+		if err != nil {
+			t.Fatalf("got err %q, want %v", err, nil)
+		}
+
+		if result != "say" {
+			t.Fatalf("got result %q, want %q", result, "say")
+		}
+	})
+
+	t.Run("closed on conn close", func(t *testing.T) {
+		conn, err := rcon.Dial(server.Addr(), "password")
+		if err != nil {
+			t.Fatalf("got err %q, want %v", err, nil)
+		}
+
+		sub := conn.Subscribe(rcon.PacketFilter{IDs: []int32{-1}}, 0)
+		conn.Close()
+
+		if _, ok := <-sub.C(); ok {
+			t.Error("got open channel, want closed")
+		}
+
+		sub.Unsubscribe()
+	})
+}
+
+func TestConn_Subscribe_Rust(t *testing.T) {
+	server := rcontest.NewServer(
+		rcontest.SetSettings(rcontest.Settings{Password: "password"}),
+		rcontest.SetCommandHandler(func(c *rcontest.Context) {
+			// Console output is pushed as type 4 packet, the response is
+			// preceded by the undocumented type 4 packet.
+			rcon.NewPacket(4, -1, "console output").WriteTo(c.Conn())
+			rcon.NewPacket(4, c.Request().ID, "").WriteTo(c.Conn())
+			rcon.NewPacket(rcon.SERVERDATA_RESPONSE_VALUE, c.Request().ID, c.Request().Body()).WriteTo(c.Conn())
+		}),
+	)
+	defer server.Close()
+
+	for _, dialect := range []rcon.Dialect{rcon.DialectGeneric, rcon.DialectRust} {
+		t.Run(dialect.Name(), func(t *testing.T) {
+			conn, err := rcon.Dial(server.Addr(), "password", rcon.SetDialect(dialect))
+			if err != nil {
+				t.Fatalf("got err %q, want %v", err, nil)
+			}
+			defer conn.Close()
+
+			sub := conn.Subscribe(rcon.PacketFilter{}, 10)
+			defer sub.Unsubscribe()
+
+			result, err := conn.Execute("status")
+			if err != nil {
+				t.Fatalf("got err %q, want %v", err, nil)
+			}
+
+			if result != "status" {
+				t.Fatalf("got result %q, want %q", result, "status")
+			}
+
+			select {
+			case packet := <-sub.C():
+				if packet.Type != 4 || packet.Body() != "console output" {
+					t.Errorf("got packet type %d with body %q, want type 4 with %q", packet.Type, packet.Body(), "console output")
+				}
+			case <-time.After(time.Second):
+				t.Fatal("got no packet, want console output")
+			}
+
+			select {
+			case packet := <-sub.C():
+				t.Errorf("got packet type %d with body %q, want none", packet.Type, packet.Body())
+			default:
+			}
+		})
+	}
+}
@@ -0,0 +1,101 @@
+package rcon
+
+import (
+	"context"
+	"crypto/sha256"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"net"
+	"slices"
+)
+
+// ErrCertificatePinMismatch is returned when the server certificate doesn't
+// match the pinned SPKI hashes.
+var ErrCertificatePinMismatch = errors.New("certificate pin mismatch")
+
+// DialTLS creates a new authorized Conn over TLS connection. It is a shortcut
+// for Dial with SetTLSConfig option.
+func DialTLS(address string, password string, config *tls.Config, options ...Option) (*Conn, error) {
+	return DialTLSContext(context.Background(), address, password, config, options...)
+}
+
+// DialTLSContext is like DialTLS but uses the provided context like
+// DialContext does.
+func DialTLSContext(
+	ctx context.Context, address string, password string, config *tls.Config, options ...Option,
+) (*Conn, error) {
+	return DialContext(ctx, address, password, append([]Option{SetTLSConfig(config)}, options...)...)
+}
+
+// SPKIHash returns the base64 encoded SHA-256 hash of the certificate
+// SubjectPublicKeyInfo, which is used to pin certificates with
+// VerifySPKIPins. It is the same value as pin-sha256 of HTTP Public Key
+// Pinning and can be obtained with openssl:
+//
+//	openssl x509 -in cert.pem -pubkey -noout | openssl pkey -pubin -outform der |
+//	openssl dgst -sha256 -binary | openssl enc -base64
+func SPKIHash(cert *x509.Certificate) string {
+	sum := sha256.Sum256(cert.RawSubjectPublicKeyInfo)
+
+	return base64.StdEncoding.EncodeToString(sum[:])
+}
+
+// VerifySPKIPins returns a function for tls.Config VerifyConnection, which
+// accepts the connection only if the server certificate has one of the
+// pinned SPKI hashes. It is called after the normal certificate
+// verification, so set InsecureSkipVerify to trust self-signed certificates
+// by pins only. In that case only the leaf certificate is checked, because
+// the rest of the chain sent by the server is not verified and could be
+// appended by anyone. Otherwise the certificates of the verified chains are
+// checked, so an intermediate or root CA may be pinned too.
+func VerifySPKIPins(pins ...string) func(state tls.ConnectionState) error {
+	return func(state tls.ConnectionState) error {
+		if len(state.VerifiedChains) == 0 {
+			if len(state.PeerCertificates) > 0 && slices.Contains(pins, SPKIHash(state.PeerCertificates[0])) {
+				return nil
+			}
+
+			return ErrCertificatePinMismatch
+		}
+
+		for _, chain := range state.VerifiedChains {
+			for _, cert := range chain {
+				if slices.Contains(pins, SPKIHash(cert)) {
+					return nil
+				}
+			}
+		}
+
+		return ErrCertificatePinMismatch
+	}
+}
+
+// handshake runs TLS client handshake over conn. The ctx carries the dial
+// timeout shared with the TCP dial. The conn is closed if the handshake
+// fails.
+func handshake(ctx context.Context, conn net.Conn, address string, settings Settings) (net.Conn, error) {
+	config := settings.tlsConfig
+
+	if config.ServerName == "" {
+		host, _, err := net.SplitHostPort(address)
+		if err != nil {
+			host = address
+		}
+
+		config = config.Clone()
+		config.ServerName = host
+	}
+
+	tlsConn := tls.Client(conn, config)
+
+	if err := tlsConn.HandshakeContext(ctx); err != nil {
+		_ = conn.Close()
+
+		return nil, fmt.Errorf("tls handshake: %w", err)
+	}
+
+	return tlsConn, nil
+}
@@ -0,0 +1,163 @@
+package rcon_test
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"testing"
+
+	"github.com/gorcon/rcon"
+	"github.com/gorcon/rcon/rcontest"
+)
+
+func TestDialTLS(t *testing.T) {
+	server := rcontest.NewTLSServer(
+		rcontest.SetSettings(rcontest.Settings{Password: "password"}),
+		rcontest.SetCommandHandler(commandHandler),
+	)
+	defer server.Close()
+
+	t.Run("custom root CAs", func(t *testing.T) {
+		conn, err := rcon.DialTLS(server.Addr(), "password", server.ClientTLSConfig())
+		if err != nil {
+			t.Fatalf("got err %q, want %v", err, nil)
+		}
+		defer conn.Close()
+
+		result, err := conn.Execute("help")
+		if err != nil {
+			t.Fatalf("got err %q, want %v", err, nil)
+		}
+
+		if resultWant := "lorem ipsum dolor sit amet"; result != resultWant {
+			t.Fatalf("got result %q, want %q", result, resultWant)
+		}
+	})
+
+	t.Run("unknown authority", func(t *testing.T) {
+		_, err := rcon.Dial(server.Addr(), "password", rcon.SetTLSConfig(&tls.Config{MinVersion: tls.VersionTLS12}))
+
+		var certErr *tls.CertificateVerificationError
+		if !errors.As(err, &certErr) {
+			t.Errorf("got err %q, want %T", err, certErr)
+		}
+	})
+
+	t.Run("server name mismatch", func(t *testing.T) {
+		config := server.ClientTLSConfig()
+		config.ServerName = "example.com"
+
+		_, err := rcon.DialTLS(server.Addr(), "password", config)
+
+		var hostErr x509.HostnameError
+		if !errors.As(err, &hostErr) {
+			t.Errorf("got err %q, want %T", err, hostErr)
+		}
+	})
+
+	t.Run("pinned certificate", func(t *testing.T) {
+		config := &tls.Config{
+			MinVersion:         tls.VersionTLS12,
+			InsecureSkipVerify: true, //nolint:gosec // Certificate is verified by pin.
+			VerifyConnection:   rcon.VerifySPKIPins(rcon.SPKIHash(server.Certificate())),
+		}
+
+		conn, err := rcon.DialTLS(server.Addr(), "password", config)
+		if err != nil {
+			t.Fatalf("got err %q, want %v", err, nil)
+		}
+		defer conn.Close()
+	})
+
+	t.Run("pin mismatch", func(t *testing.T) {
+		config := server.ClientTLSConfig()
+		config.VerifyConnection = rcon.VerifySPKIPins("47DEQpj8HBSa+/TImW+5JCeuQeRkm5NMpJWZG3hSuFU=")
+
+		if _, err := rcon.DialTLS(server.Addr(), "password", config); !errors.Is(err, rcon.ErrCertificatePinMismatch) {
+			t.Errorf("got err %q, want %q", err, rcon.ErrCertificatePinMismatch)
+		}
+	})
+}
+
+func TestVerifySPKIPins_AppendedCertificate(t *testing.T) {
+	pinned, err := rcontest.GenerateCertificate("127.0.0.1")
+	if err != nil {
+		t.Fatalf("got err %q, want %v", err, nil)
+	}
+
+	attacker, err := rcontest.GenerateCertificate("127.0.0.1")
+	if err != nil {
+		t.Fatalf("got err %q, want %v", err, nil)
+	}
+
+	// The attacker sends its own leaf followed by the pinned certificate.
+	attacker.Certificate = append(attacker.Certificate, pinned.Certificate[0])
+
+	server := rcontest.NewUnstartedServer(
+		rcontest.SetSettings(rcontest.Settings{Password: "password"}),
+		rcontest.SetCommandHandler(commandHandler),
+	)
+	server.TLS = &tls.Config{MinVersion: tls.VersionTLS12, Certificates: []tls.Certificate{attacker}}
+	server.StartTLS()
+	defer server.Close()
+
+	config := &tls.Config{
+		MinVersion:         tls.VersionTLS12,
+		InsecureSkipVerify: true, //nolint:gosec // Certificate is verified by pin.
+		VerifyConnection:   rcon.VerifySPKIPins(rcon.SPKIHash(pinned.Leaf)),
+	}
+
+	conn, err := rcon.DialTLS(server.Addr(), "password", config)
+	if err == nil {
+		conn.Close()
+	}
+
+	if !errors.Is(err, rcon.ErrCertificatePinMismatch) {
+		t.Errorf("got err %v, want %q", err, rcon.ErrCertificatePinMismatch)
+	}
+}
+
+func TestDialTLS_ClientCertificate(t *testing.T) {
+	clientCert, err := rcontest.GenerateCertificate("client")
+	if err != nil {
+		t.Fatalf("got err %q, want %v", err, nil)
+	}
+
+	clientCAs := x509.NewCertPool()
+	clientCAs.AddCert(clientCert.Leaf)
+
+	server := rcontest.NewUnstartedServer(
+		rcontest.SetSettings(rcontest.Settings{Password: "password"}),
+		rcontest.SetCommandHandler(commandHandler),
+	)
+	server.TLS = &tls.Config{
+		MinVersion: tls.VersionTLS12,
+		ClientAuth: tls.RequireAndVerifyClientCert,
+		ClientCAs:  clientCAs,
+	}
+	server.StartTLS()
+	defer server.Close()
+
+	t.Run("with certificate", func(t *testing.T) {
+		config := server.ClientTLSConfig()
+		config.Certificates = []tls.Certificate{clientCert}
+
+		conn, err := rcon.DialTLS(server.Addr(), "password", config)
+		if err != nil {
+			t.Fatalf("got err %q, want %v", err, nil)
+		}
+		defer conn.Close()
+
+		if _, err := conn.Execute("help"); err != nil {
+			t.Fatalf("got err %q, want %v", err, nil)
+		}
+	})
+
+	t.Run("without certificate", func(t *testing.T) {
+		// TLS 1.3 client learns about the rejected certificate after the
+		// handshake, so the error may be reported by auth.
+		if _, err := rcon.DialTLS(server.Addr(), "password", server.ClientTLSConfig()); err == nil {
+			t.Error("got no error, want rejected connection")
+		}
+	})
+}
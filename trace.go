@@ -0,0 +1,82 @@
+package rcon
+
+import (
+	"context"
+	"strings"
+)
+
+// Span names started by Conn.
+const (
+	SpanDial    = "rcon.Dial"
+	SpanAuth    = "rcon.Auth"
+	SpanExecute = "rcon.Execute"
+)
+
+// Span attribute keys set by Conn.
+const (
+	AttributeServerAddress = "server.address"
+	AttributeCommand       = "rcon.command"
+	AttributePacketID      = "rcon.packet.id"
+	AttributeResponseSize  = "rcon.response.size"
+)
+
+// Attribute is a key-value pair describing a span.
+type Attribute struct {
+	Key   string
+	Value any
+}
+
+// Tracer starts spans around Dial, auth and Execute. Its shape follows
+// OpenTelemetry trace.Tracer, so an adapter is a few lines of code. The span
+// started from the context passed to DialContext, ExecuteContext or Do is
+// the parent of the Conn spans.
+type Tracer interface {
+	// Start creates a span and a context containing it.
+	Start(ctx context.Context, name string, attributes ...Attribute) (context.Context, Span)
+}
+
+// Span is an operation started by Tracer. Its shape follows OpenTelemetry
+// trace.Span.
+type Span interface {
+	// SetAttributes sets attributes of the span.
+	SetAttributes(attributes ...Attribute)
+
+	// RecordError records the error the operation failed with.
+	RecordError(err error)
+
+	// End completes the span.
+	End()
+}
+
+// commandName returns the command without arguments, so secrets passed as
+// arguments are not traced.
+func commandName(command string) string {
+	if fields := strings.Fields(command); len(fields) > 0 {
+		return fields[0]
+	}
+
+	return ""
+}
+
+// endSpan records err if it is not nil and ends the span.
+func endSpan(span Span, err error) {
+	if err != nil {
+		span.RecordError(err)
+	}
+
+	span.End()
+}
+
+// noTracer is Tracer which starts no spans.
+type noTracer struct{}
+
+func (noTracer) Start(ctx context.Context, _ string, _ ...Attribute) (context.Context, Span) {
+	return ctx, noSpan{}
+}
+
+// noSpan is Span which records nothing.
+type noSpan struct{}
+
+func (noSpan) SetAttributes(...Attribute) {}
+func (noSpan) RecordError(error)          {}
+func (noSpan) End()                       {}
@@ -0,0 +1,146 @@
+package rcon_test
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+
+	"github.com/gorcon/rcon"
+	"github.com/gorcon/rcon/rcontest"
+)
+
+type spanKey struct{}
+
+// recordingTracer records started spans.
+type recordingTracer struct {
+	mu    sync.Mutex
+	spans []*recordingSpan
+}
+
+type recordingSpan struct {
+	name       string
+	parent     *recordingSpan
+	attributes map[string]any
+	err        error
+	ended      bool
+}
+
+func (t *recordingTracer) Start(
+	ctx context.Context, name string, attributes ...rcon.Attribute,
+) (context.Context, rcon.Span) {
+	parent, _ := ctx.Value(spanKey{}).(*recordingSpan)
+
+	span := &recordingSpan{name: name, parent: parent, attributes: make(map[string]any)}
+	span.SetAttributes(attributes...)
+
+	t.mu.Lock()
+	t.spans = append(t.spans, span)
+	t.mu.Unlock()
+
+	return context.WithValue(ctx, spanKey{}, span), span
+}
+
+func (t *recordingTracer) find(name string) []*recordingSpan {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	var spans []*recordingSpan
+
+	for _, span := range t.spans {
+		if span.name == name {
+			spans = append(spans, span)
+		}
+	}
+
+	return spans
+}
+
+func (s *recordingSpan) SetAttributes(attributes ...rcon.Attribute) {
+	for _, a := range attributes {
+		s.attributes[a.Key] = a.Value
+	}
+}
+
+func (s *recordingSpan) RecordError(err error) {
+	s.err = err
+}
+
+func (s *recordingSpan) End() {
+	s.ended = true
+}
+
+func TestSetTracer(t *testing.T) {
+	server := rcontest.NewServer(
+		rcontest.SetSettings(rcontest.Settings{Password: "password"}),
+		rcontest.SetCommandHandler(commandHandler),
+	)
+	defer server.Close()
+
+	tracer := &recordingTracer{}
+	root := &recordingSpan{name: "request", attributes: make(map[string]any)}
+	ctx := context.WithValue(context.Background(), spanKey{}, root)
+
+	conn, err := rcon.DialContext(ctx, server.Addr(), "password", rcon.SetTracer(tracer))
+	if err != nil {
+		t.Fatalf("got err %q, want %v", err, nil)
+	}
+	defer conn.Close()
+
+	response, err := conn.Do(ctx, "help me please")
+	if err != nil {
+		t.Fatalf("got err %q, want %v", err, nil)
+	}
+
+	if _, err := conn.Do(ctx, "another"); !errors.Is(err, rcon.ErrInvalidPacketID) {
+		t.Fatalf("got err %q, want %q", err, rcon.ErrInvalidPacketID)
+	}
+
+	t.Run("dial and auth", func(t *testing.T) {
+		dials, auths := tracer.find(rcon.SpanDial), tracer.find(rcon.SpanAuth)
+		if len(dials) != 1 || len(auths) != 1 {
+			t.Fatalf("got %d dial and %d auth spans, want 1 and 1", len(dials), len(auths))
+		}
+
+		if dials[0].parent != root || auths[0].parent != dials[0] {
+			t.Error("got broken span hierarchy, want request > dial > auth")
+		}
+
+		if dials[0].attributes[rcon.AttributeServerAddress] != server.Addr() || !dials[0].ended {
+			t.Errorf("got dial span %+v, want ended with server address", dials[0])
+		}
+
+		if auths[0].attributes[rcon.AttributePacketID] != int32(1) {
+			t.Errorf("got auth span attributes %v, want packet id 1", auths[0].attributes)
+		}
+	})
+
+	t.Run("execute", func(t *testing.T) {
+		spans := tracer.find(rcon.SpanExecute)
+		if len(spans) != 2 {
+			t.Fatalf("got %d execute spans, want 2", len(spans))
+		}
+
+		span := spans[0]
+		if span.parent != root || !span.ended || span.err != nil {
+			t.Errorf("got execute span %+v, want ended child of request without error", span)
+		}
+
+		want := map[string]any{
+			rcon.AttributeServerAddress: server.Addr(),
+			rcon.AttributeCommand:       "help",
+			rcon.AttributePacketID:      response.ID,
+			rcon.AttributeResponseSize:  len(response.Body),
+		}
+
+		for key, value := range want {
+			if span.attributes[key] != value {
+				t.Errorf("got attribute %s %v, want %v", key, span.attributes[key], value)
+			}
+		}
+
+		if !errors.Is(spans[1].err, rcon.ErrInvalidPacketID) {
+			t.Errorf("got span error %q, want %q", spans[1].err, rcon.ErrInvalidPacketID)
+		}
+	})
+}